@@ -25,19 +25,29 @@ import (
 type AsyncWriter struct {
 	LogWriter
 	done    *sync.WaitGroup
-	ch      chan []byte
+	ch      chan *Buffer
 	flush   chan bool
 	flushed chan error
+	stopped chan struct{}
 	omit    bool
 }
 
+const defaultAsyncQueueSize = 1024
+
 func NewAsyncWriter(w LogWriter, omit bool) LogWriter {
+	return NewAsyncWriterSize(w, omit, defaultAsyncQueueSize)
+}
+
+// NewAsyncWriterSize creates an AsyncWriter queueing at most size entries,
+// when omit is true entries are discarded instead of blocking if it is full.
+func NewAsyncWriterSize(w LogWriter, omit bool, size int) LogWriter {
 	asyncWriter := &AsyncWriter{
 		LogWriter: w,
 		done:      &sync.WaitGroup{},
-		ch:        make(chan []byte, 1024),
+		ch:        make(chan *Buffer, size),
 		flush:     make(chan bool),
 		flushed:   make(chan error),
+		stopped:   make(chan struct{}),
 		omit:      omit,
 	}
 	go asyncWriter.runWorker()
@@ -45,48 +55,70 @@ func NewAsyncWriter(w LogWriter, omit bool) LogWriter {
 }
 
 func (w *AsyncWriter) runWorker() {
+	defer close(w.stopped)
 	for {
 		select {
 		case formatLog, ok := <-w.ch:
 			if !ok {
 				return
 			}
-			err := w.LogWriter.Write(formatLog)
-			if err != nil {
-				_, _ = fmt.Fprintf(os.Stderr, "log async writes error: %s\n", err)
-			}
-			w.done.Done()
+			w.write(formatLog)
 		case <-w.flush:
-			for i := 0; i < len(w.ch); i++ {
-				formatLog := <-w.ch
-				err := w.LogWriter.Write(formatLog)
-				if err != nil {
-					_, _ = fmt.Fprintf(os.Stderr, "log async writes error: %s\n", err)
-				}
-				w.done.Done()
+			for n := len(w.ch); n > 0; n-- {
+				w.write(<-w.ch)
 			}
 			w.flushed <- w.LogWriter.Flush()
 		}
 	}
 }
 
+func (w *AsyncWriter) write(formatLog *Buffer) {
+	err := writeLevel(w.LogWriter, formatLog.Level(), formatLog.Bytes())
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "log async writes error: %s\n", err)
+	}
+	formatLog.Free()
+	w.done.Done()
+}
+
+// Write copies log into a Buffer and queues it.
 func (w *AsyncWriter) Write(log []byte) error {
+	return w.WriteLevel(InvalidLevel, log)
+}
+
+// WriteLevel copies log into a Buffer of level and queues it.
+func (w *AsyncWriter) WriteLevel(level Level, log []byte) error {
+	b := NewBuffer()
+	_, _ = b.Write(log)
+	b.SetLevel(level)
+	return w.WriteBuffer(b)
+}
+
+// WriteBuffer queues b without copying, b is freed once it is written.
+func (w *AsyncWriter) WriteBuffer(b *Buffer) error {
 	w.done.Add(1)
 	if w.omit {
 		select {
-		case w.ch <- log:
+		case w.ch <- b:
 		default:
+			b.Free()
 			w.done.Done()
 		}
 	} else {
-		w.ch <- log
+		w.ch <- b
 	}
 	return nil
 }
 
+// Flush writes the queued entries and flushes the wrapped writer, it only
+// flushes the wrapped writer once the AsyncWriter is closed.
 func (w *AsyncWriter) Flush() error {
-	w.flush <- true
-	return <-w.flushed
+	select {
+	case w.flush <- true:
+		return <-w.flushed
+	case <-w.stopped:
+		return w.LogWriter.Flush()
+	}
 }
 
 func (w *AsyncWriter) Close() error {
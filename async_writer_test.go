@@ -31,3 +31,11 @@ func TestAsyncWriter(t *testing.T) {
 	_ = aw.Flush()
 	assert.Equal(t, "DEBUG - logger.go:12 - test asyncWriter", aw.(*AsyncWriter).LogWriter.(*BufferWriter).String())
 }
+
+func TestAsyncWriterFlushAfterClose(t *testing.T) {
+	aw := NewAsyncWriter(new(BufferWriter), false)
+	assert.Nil(t, aw.Write([]byte("closed")))
+	assert.Nil(t, aw.Close())
+	assert.Nil(t, aw.Flush())
+	assert.Equal(t, "closed", aw.(*AsyncWriter).LogWriter.(*BufferWriter).String())
+}
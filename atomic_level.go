@@ -0,0 +1,86 @@
+/*
+ * Copyright 2022 ByteDance and/or its affiliates.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dyclog
+
+import (
+	"sync/atomic"
+)
+
+// AtomicLevel is a Level which can be shared by several loggers and changed
+// concurrently, updating it changes the level of all of them.
+type AtomicLevel struct {
+	level int64
+}
+
+// NewAtomicLevel creates an AtomicLevel set to level.
+func NewAtomicLevel(level Level) *AtomicLevel {
+	return &AtomicLevel{
+		level: int64(level),
+	}
+}
+
+func (a *AtomicLevel) Level() Level {
+	return Level(atomic.LoadInt64(&a.level))
+}
+
+func (a *AtomicLevel) SetLevel(level Level) {
+	atomic.StoreInt64(&a.level, int64(level))
+}
+
+// Enabled reports whether entries of level pass this level.
+func (a *AtomicLevel) Enabled(level Level) bool {
+	return level >= a.Level()
+}
+
+func (a *AtomicLevel) String() string {
+	return a.Level().String()
+}
+
+// Set implements flag.Value.
+func (a *AtomicLevel) Set(s string) error {
+	return a.UnmarshalText([]byte(s))
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (a *AtomicLevel) MarshalText() ([]byte, error) {
+	return a.Level().MarshalText()
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (a *AtomicLevel) UnmarshalText(text []byte) error {
+	var l Level
+	if err := l.UnmarshalText(text); err != nil {
+		return err
+	}
+	a.SetLevel(l)
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler.
+func (a *AtomicLevel) MarshalJSON() ([]byte, error) {
+	return a.Level().MarshalJSON()
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (a *AtomicLevel) UnmarshalJSON(data []byte) error {
+	var l Level
+	if err := l.UnmarshalJSON(data); err != nil {
+		return err
+	}
+	a.SetLevel(l)
+	return nil
+}
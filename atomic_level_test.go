@@ -0,0 +1,73 @@
+/*
+ * Copyright 2022 ByteDance and/or its affiliates.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dyclog
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAtomicLevel(t *testing.T) {
+	level := NewAtomicLevel(INFO)
+	first := NewLogger(new(BufferWriter))
+	second := NewLogger(new(BufferWriter))
+	first.SetAtomicLevel(level)
+	second.SetAtomicLevel(level)
+
+	first.Debug("dropped")
+	assert.Equal(t, "", first.GetWriter().(*BufferWriter).String())
+
+	assert.Nil(t, json.Unmarshal([]byte(`"debug"`), level))
+	assert.Equal(t, DEBUG, second.GetLevel())
+	second.Debug("written")
+	assert.Contains(t, second.GetWriter().(*BufferWriter).String(), "written")
+
+	first.SetLevel(ERROR)
+	assert.Equal(t, ERROR, second.GetLevel())
+	assert.Equal(t, "ERROR", level.String())
+	b, err := json.Marshal(level)
+	assert.Nil(t, err)
+	assert.Equal(t, `"ERROR"`, string(b))
+
+	assert.NotNil(t, level.Set("loud"))
+	assert.Nil(t, level.Set("warn"))
+	assert.Equal(t, WARN, first.GetLevel())
+}
+
+func TestSetAtomicLevelConcurrent(t *testing.T) {
+	logger := NewLogger(new(BufferWriter))
+	logger.SetAtomicLevel(nil)
+	assert.Equal(t, DEBUG, logger.GetLevel())
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			logger.SetAtomicLevel(NewAtomicLevel(ERROR))
+		}
+	}()
+	for i := 0; i < 100; i++ {
+		logger.Enabled(context.Background(), INFO)
+	}
+	wg.Wait()
+	assert.Equal(t, ERROR, logger.GetLevel())
+}
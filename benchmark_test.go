@@ -45,3 +45,59 @@ func BenchmarkConsoleLogCtxInfo(b *testing.B) {
 		}
 	})
 }
+
+type discardWriter struct{}
+
+func (discardWriter) Write([]byte) error { return nil }
+func (discardWriter) Flush() error       { return nil }
+func (discardWriter) Close() error       { return nil }
+
+func BenchmarkGetCaller(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = GetCaller(1)
+	}
+}
+
+func BenchmarkLogInfo(b *testing.B) {
+	logger := NewLogger(discardWriter{})
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		logger.Info("Hello, Benchmark!")
+	}
+}
+
+func BenchmarkLogInfoWithoutCaller(b *testing.B) {
+	logger := NewLogger(discardWriter{})
+	logger.SetCaller(false)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		logger.Info("Hello, Benchmark!")
+	}
+}
+
+func BenchmarkLogDisabled(b *testing.B) {
+	logger := NewLogger(discardWriter{})
+	logger.SetLevel(ERROR)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		logger.Info("Hello, Benchmark!")
+	}
+}
+
+func BenchmarkAsyncLogInfo(b *testing.B) {
+	logger := NewLogger(NewAsyncWriter(discardWriter{}, false))
+	defer logger.Close()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		logger.Info("Hello, Benchmark!")
+	}
+}
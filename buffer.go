@@ -0,0 +1,111 @@
+/*
+ * Copyright 2022 ByteDance and/or its affiliates.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dyclog
+
+import (
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	defaultBufferSize = 256
+	maxPooledBufSize  = 64 * 1024
+)
+
+var bufferPool = sync.Pool{
+	New: func() interface{} {
+		return &Buffer{b: make([]byte, 0, defaultBufferSize), level: InvalidLevel}
+	},
+}
+
+// Buffer is a pooled byte buffer a Formatter writes an entry into. Whoever
+// owns a Buffer must call Free exactly once after it is no longer used.
+type Buffer struct {
+	b     []byte
+	level Level
+}
+
+// NewBuffer gets an empty Buffer from the pool.
+func NewBuffer() *Buffer {
+	return bufferPool.Get().(*Buffer)
+}
+
+// Free resets the Buffer and puts it back to the pool, the Buffer and
+// the slices returned by Bytes must not be used afterwards.
+func (b *Buffer) Free() {
+	if cap(b.b) > maxPooledBufSize {
+		return
+	}
+	b.b = b.b[:0]
+	b.level = InvalidLevel
+	bufferPool.Put(b)
+}
+
+// Level returns the level of the entry in the Buffer, or InvalidLevel if it
+// is unknown.
+func (b *Buffer) Level() Level {
+	return b.level
+}
+
+// SetLevel sets the level of the entry in the Buffer.
+func (b *Buffer) SetLevel(level Level) {
+	b.level = level
+}
+
+func (b *Buffer) Bytes() []byte {
+	return b.b
+}
+
+func (b *Buffer) String() string {
+	return string(b.b)
+}
+
+func (b *Buffer) Len() int {
+	return len(b.b)
+}
+
+func (b *Buffer) Reset() {
+	b.b = b.b[:0]
+}
+
+func (b *Buffer) Write(p []byte) (int, error) {
+	b.b = append(b.b, p...)
+	return len(p), nil
+}
+
+func (b *Buffer) WriteString(s string) (int, error) {
+	b.b = append(b.b, s...)
+	return len(s), nil
+}
+
+func (b *Buffer) WriteByte(c byte) error {
+	b.b = append(b.b, c)
+	return nil
+}
+
+func (b *Buffer) AppendInt(i int64) {
+	b.b = strconv.AppendInt(b.b, i, 10)
+}
+
+func (b *Buffer) AppendQuote(s string) {
+	b.b = strconv.AppendQuote(b.b, s)
+}
+
+func (b *Buffer) AppendTime(t time.Time, layout string) {
+	b.b = t.AppendFormat(b.b, layout)
+}
@@ -0,0 +1,243 @@
+/*
+ * Copyright 2022 ByteDance and/or its affiliates.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dyclog
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config describes a Logger, it can be decoded from JSON or YAML.
+type Config struct {
+	Level     Level           `json:"level" yaml:"level"`
+	Formatter FormatterConfig `json:"formatter" yaml:"formatter"`
+	// Writers receive every entry, it defaults to a console writer.
+	Writers []WriterConfig `json:"writers" yaml:"writers"`
+}
+
+// FormatterConfig describes the formatter of a Logger.
+type FormatterConfig struct {
+	// Type is text, json, logfmt or a name passed to RegisterFormatter,
+	// text by default.
+	Type  string `json:"type" yaml:"type"`
+	Color bool   `json:"color" yaml:"color"`
+	Quote bool   `json:"quote" yaml:"quote"`
+	// Timestamp defaults to false for text and true for json and logfmt.
+	Timestamp       *bool  `json:"timestamp" yaml:"timestamp"`
+	TimestampFormat string `json:"timestamp_format" yaml:"timestamp_format"`
+	DisableIP       bool   `json:"disable_ip" yaml:"disable_ip"`
+	Function        bool   `json:"function" yaml:"function"`
+	// CallerFormat is one of short, package and full, short by default.
+	CallerFormat     string `json:"caller_format" yaml:"caller_format"`
+	CallerTrimPrefix string `json:"caller_trim_prefix" yaml:"caller_trim_prefix"`
+	// Options are passed to the factory registered for Type, see
+	// RegisterFormatter. The fields above override them when set.
+	Options Options `json:"options" yaml:"options"`
+}
+
+// WriterConfig describes one writer of a Logger.
+type WriterConfig struct {
+	// Type is console, file, buffer or a name passed to RegisterWriter.
+	Type string `json:"type" yaml:"type"`
+	// Output is stdout or stderr for console writers, stdout by default.
+	Output string `json:"output" yaml:"output"`
+	// The following fields configure file writers. Rotation is daily, hourly,
+	// minutely or a duration like 6h, daily by default, in Location, an IANA
+	// time zone name, the local one by default. TimeLayout and
+	// FilenamePattern name rotated files, see SetTimeLayout and
+	// SetFilenamePattern. MaxAge is a duration like 168h, CompressLevel is
+	// a compress/gzip level, 0 means the default one. DegradedRetry is
+	// a duration enabling the degraded mode, see SetDegradedMode, ReopenCheck
+	// the interval of SetReopenCheck. BufferSize, 0 for the default one, and
+	// FlushInterval configure buffering, Sync is never, error, always or the
+	// interval of SyncPeriodically, see SyncPolicy. ReopenSignals are names
+	// like SIGHUP or SIGUSR1, see SetReopenSignals.
+	Filename        string   `json:"filename" yaml:"filename"`
+	Rotation        string   `json:"rotation" yaml:"rotation"`
+	Location        string   `json:"location" yaml:"location"`
+	TimeLayout      string   `json:"time_layout" yaml:"time_layout"`
+	FilenamePattern string   `json:"filename_pattern" yaml:"filename_pattern"`
+	LimitFiles      int      `json:"limit_files" yaml:"limit_files"`
+	MaxFileSize     int64    `json:"max_file_size" yaml:"max_file_size"`
+	MaxAge          string   `json:"max_age" yaml:"max_age"`
+	MaxTotalSize    int64    `json:"max_total_size" yaml:"max_total_size"`
+	Compress        bool     `json:"compress" yaml:"compress"`
+	CompressLevel   int      `json:"compress_level" yaml:"compress_level"`
+	DegradedRetry   string   `json:"degraded_retry" yaml:"degraded_retry"`
+	ReopenCheck     string   `json:"reopen_check" yaml:"reopen_check"`
+	ReopenSignals   []string `json:"reopen_signals" yaml:"reopen_signals"`
+	BufferSize      int      `json:"buffer_size" yaml:"buffer_size"`
+	FlushInterval   string   `json:"flush_interval" yaml:"flush_interval"`
+	Sync            string   `json:"sync" yaml:"sync"`
+	// Async wraps the writer into an AsyncWriter if set.
+	Async *AsyncConfig `json:"async" yaml:"async"`
+	// Options are passed to the factory registered for Type, see
+	// RegisterWriter. The fields above override them when set.
+	Options Options `json:"options" yaml:"options"`
+}
+
+// AsyncConfig describes the AsyncWriter wrapping a writer.
+type AsyncConfig struct {
+	Omit      bool `json:"omit" yaml:"omit"`
+	QueueSize int  `json:"queue_size" yaml:"queue_size"`
+}
+
+// LoadConfig reads a Config from a YAML file if filename ends with .yaml
+// or .yml, or from a JSON file otherwise.
+func LoadConfig(filename string) (*Config, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	return parseConfig(filename, data)
+}
+
+// parseConfig parses data read from filename, see LoadConfig.
+func parseConfig(filename string, data []byte) (*Config, error) {
+	cfg := &Config{}
+	var err error
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, cfg)
+	default:
+		err = json.Unmarshal(data, cfg)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parse config %s: %w", filename, err)
+	}
+	return cfg, nil
+}
+
+// NewLoggerFromConfig creates a Logger described by cfg.
+func NewLoggerFromConfig(cfg *Config) (*Logger, error) {
+	formatter, writer, err := cfg.build()
+	if err != nil {
+		return nil, err
+	}
+	logger := NewLogger(writer)
+	logger.SetFormatter(formatter)
+	logger.SetLevel(cfg.Level)
+	return logger, nil
+}
+
+// ApplyConfig replaces the level, the formatter and the writers of the
+// logger with the ones described by cfg. The previous writer is closed
+// after the entries written to it are flushed, so no entry is dropped.
+func (logger *Logger) ApplyConfig(cfg *Config) error {
+	formatter, writer, err := cfg.build()
+	if err != nil {
+		return err
+	}
+	old := logger.replace(formatter, writer)
+	logger.SetLevel(cfg.Level)
+	return old.Close()
+}
+
+func (cfg *Config) build() (Formatter, LogWriter, error) {
+	formatter, err := cfg.Formatter.build()
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(cfg.Writers) == 0 {
+		return formatter, NewConsoleWriter(), nil
+	}
+	writers := make([]LogWriter, 0, len(cfg.Writers))
+	for _, wc := range cfg.Writers {
+		w, err := wc.build()
+		if err != nil {
+			for _, built := range writers {
+				_ = built.Close()
+			}
+			return nil, nil, err
+		}
+		writers = append(writers, w)
+	}
+	return formatter, NewMultiWriter(writers...), nil
+}
+
+func (fc *FormatterConfig) build() (Formatter, error) {
+	options := fc.Options.clone()
+	options.setIf("color", fc.Color, fc.Color)
+	options.setIf("quote", fc.Quote, fc.Quote)
+	options.setIf("timestamp", fc.Timestamp != nil, fc.Timestamp != nil && *fc.Timestamp)
+	options.setIf("timestamp_format", fc.TimestampFormat != "", fc.TimestampFormat)
+	options.setIf("disable_ip", fc.DisableIP, fc.DisableIP)
+	options.setIf("function", fc.Function, fc.Function)
+	options.setIf("caller_format", fc.CallerFormat != "", fc.CallerFormat)
+	options.setIf("caller_trim_prefix", fc.CallerTrimPrefix != "", fc.CallerTrimPrefix)
+
+	name := fc.Type
+	if name == "" {
+		name = "text"
+	}
+	return NewFormatter(name, options)
+}
+
+func (wc *WriterConfig) build() (LogWriter, error) {
+	options := wc.Options.clone()
+	options.setIf("output", wc.Output != "", wc.Output)
+	options.setIf("filename", wc.Filename != "", wc.Filename)
+	options.setIf("rotation", wc.Rotation != "", wc.Rotation)
+	options.setIf("location", wc.Location != "", wc.Location)
+	options.setIf("time_layout", wc.TimeLayout != "", wc.TimeLayout)
+	options.setIf("filename_pattern", wc.FilenamePattern != "", wc.FilenamePattern)
+	options.setIf("limit_files", wc.LimitFiles != 0, wc.LimitFiles)
+	options.setIf("max_file_size", wc.MaxFileSize != 0, wc.MaxFileSize)
+	options.setIf("max_age", wc.MaxAge != "", wc.MaxAge)
+	options.setIf("max_total_size", wc.MaxTotalSize != 0, wc.MaxTotalSize)
+	options.setIf("compress", wc.Compress, wc.Compress)
+	options.setIf("compress_level", wc.CompressLevel != 0, wc.CompressLevel)
+	options.setIf("degraded_retry", wc.DegradedRetry != "", wc.DegradedRetry)
+	options.setIf("reopen_check", wc.ReopenCheck != "", wc.ReopenCheck)
+	options.setIf("reopen_signals", len(wc.ReopenSignals) > 0, wc.ReopenSignals)
+	options.setIf("buffer_size", wc.BufferSize != 0, wc.BufferSize)
+	options.setIf("flush_interval", wc.FlushInterval != "", wc.FlushInterval)
+	options.setIf("sync", wc.Sync != "", wc.Sync)
+
+	writer, err := NewWriter(wc.Type, options)
+	if err != nil {
+		return nil, err
+	}
+	if wc.Async != nil {
+		size := wc.Async.QueueSize
+		if size <= 0 {
+			size = defaultAsyncQueueSize
+		}
+		writer = NewAsyncWriterSize(writer, wc.Async.Omit, size)
+	}
+	return writer, nil
+}
+
+func (o Options) clone() Options {
+	c := make(Options, len(o))
+	for k, v := range o {
+		c[k] = v
+	}
+	return c
+}
+
+// setIf sets key to value if set is true.
+func (o Options) setIf(key string, set bool, value interface{}) {
+	if set {
+		o[key] = value
+	}
+}
@@ -0,0 +1,141 @@
+/*
+ * Copyright 2022 ByteDance and/or its affiliates.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dyclog
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func countLines(t *testing.T, filename string) int {
+	data, err := ioutil.ReadFile(filename)
+	assert.Nil(t, err)
+	return strings.Count(string(data), "\n")
+}
+
+func TestLoadConfig(t *testing.T) {
+	dir := t.TempDir()
+
+	yamlFile := filepath.Join(dir, "log.yaml")
+	assert.Nil(t, ioutil.WriteFile(yamlFile, []byte(`
+level: warn
+formatter:
+  type: json
+  timestamp: false
+  disable_ip: true
+writers:
+  - type: file
+    filename: `+filepath.Join(dir, "dyc.log")+`
+    rotation: hourly
+    async:
+      queue_size: 16
+`), 0644))
+	cfg, err := LoadConfig(yamlFile)
+	assert.Nil(t, err)
+	assert.Equal(t, WARN, cfg.Level)
+	assert.Equal(t, 16, cfg.Writers[0].Async.QueueSize)
+
+	logger, err := NewLoggerFromConfig(cfg)
+	assert.Nil(t, err)
+	assert.IsType(t, &AsyncWriter{}, logger.GetWriter())
+	logger.Info("dropped")
+	logger.Error("written")
+	assert.Nil(t, logger.Close())
+	data, err := ioutil.ReadFile(filepath.Join(dir, "dyc.log"))
+	assert.Nil(t, err)
+	assert.Equal(t, `{"level":"ERROR","logid":"-","location":"config_test.go:62","message":"written"}`+"\n", string(data))
+
+	jsonFile := filepath.Join(dir, "log.json")
+	assert.Nil(t, ioutil.WriteFile(jsonFile, []byte(`{"level": "info", "writers": [{"type": "pipe"}]}`), 0644))
+	cfg, err = LoadConfig(jsonFile)
+	assert.Nil(t, err)
+	_, err = NewLoggerFromConfig(cfg)
+	assert.NotNil(t, err)
+}
+
+func TestApplyConfigKeepsEntries(t *testing.T) {
+	dir := t.TempDir()
+
+	fileConfig := func(name string) *Config {
+		return &Config{
+			Level:   INFO,
+			Writers: []WriterConfig{{Type: "file", Filename: filepath.Join(dir, name), Async: &AsyncConfig{}}},
+		}
+	}
+	logger, err := NewLoggerFromConfig(fileConfig("first.log"))
+	assert.Nil(t, err)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 500; j++ {
+				logger.Info("entry %d", j)
+			}
+		}()
+	}
+	time.Sleep(time.Millisecond)
+	assert.Nil(t, logger.ApplyConfig(fileConfig("second.log")))
+	wg.Wait()
+	assert.Nil(t, logger.Close())
+
+	assert.Equal(t, 2000, countLines(t, filepath.Join(dir, "first.log"))+countLines(t, filepath.Join(dir, "second.log")))
+}
+
+func TestWatchConfig(t *testing.T) {
+	dir := t.TempDir()
+
+	filename := filepath.Join(dir, "log.json")
+	assert.Nil(t, ioutil.WriteFile(filename, []byte(`{"level": "info"}`), 0644))
+	cfg, err := LoadConfig(filename)
+	assert.Nil(t, err)
+	logger, err := NewLoggerFromConfig(cfg)
+	assert.Nil(t, err)
+
+	w, err := WatchConfig(logger, filename, 10*time.Millisecond)
+	assert.Nil(t, err)
+	defer w.Close()
+
+	assert.Nil(t, ioutil.WriteFile(filename, []byte(`{"level": "error", "formatter": {"type": "logfmt"}}`), 0644))
+	assert.Eventually(t, func() bool {
+		return logger.GetLevel() == ERROR
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestWatchConfigStableContent(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "log.json")
+	assert.Nil(t, ioutil.WriteFile(filename, []byte(`{"level": "info"}`), 0644))
+	logger := NewLogger(new(BufferWriter))
+	logger.SetLevel(INFO)
+	w := &ConfigWatcher{logger: logger, filename: filename, applied: checksum([]byte(`{"level": "info"}`))}
+
+	assert.Nil(t, ioutil.WriteFile(filename, []byte(`{"level": "err`), 0644))
+	assert.Nil(t, w.check())
+	assert.Nil(t, ioutil.WriteFile(filename, []byte(`{"level": "error"}`), 0644))
+	assert.Nil(t, w.check())
+	assert.Equal(t, INFO, logger.GetLevel())
+	assert.Nil(t, w.check())
+	assert.Equal(t, ERROR, logger.GetLevel())
+	assert.Nil(t, w.check())
+}
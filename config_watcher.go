@@ -0,0 +1,120 @@
+/*
+ * Copyright 2022 ByteDance and/or its affiliates.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dyclog
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+)
+
+const defaultWatchInterval = 5 * time.Second
+
+// ConfigWatcher polls a config file and applies it to a live Logger every
+// time the file changes, invalid configs are reported to stderr and ignored.
+// A change is applied once two polls in a row read the same content, so a
+// file caught while being written is not applied half-written.
+type ConfigWatcher struct {
+	logger   *Logger
+	filename string
+	interval time.Duration
+
+	// applied and pending are the SHA-256 of the last applied content and
+	// of the changed content read by the previous poll.
+	applied []byte
+	pending []byte
+
+	done chan bool
+	sync.WaitGroup
+}
+
+// WatchConfig starts watching filename, checking it every interval. The
+// current content is expected to be applied already, e.g. by NewLoggerFromConfig.
+func WatchConfig(logger *Logger, filename string, interval time.Duration) (*ConfigWatcher, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	if interval <= 0 {
+		interval = defaultWatchInterval
+	}
+	w := &ConfigWatcher{
+		logger:   logger,
+		filename: filename,
+		interval: interval,
+		applied:  checksum(data),
+		done:     make(chan bool),
+	}
+	w.Add(1)
+	go w.run()
+	return w, nil
+}
+
+func (w *ConfigWatcher) run() {
+	defer w.Done()
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-w.done:
+			return
+		case <-ticker.C:
+			if err := w.check(); err != nil {
+				_, _ = fmt.Fprintf(os.Stderr, "log reloads config %s error: %s\n", w.filename, err)
+			}
+		}
+	}
+}
+
+func (w *ConfigWatcher) check() error {
+	data, err := ioutil.ReadFile(w.filename)
+	if err != nil {
+		return err
+	}
+	sum := checksum(data)
+	if bytes.Equal(sum, w.applied) {
+		w.pending = nil
+		return nil
+	}
+	if !bytes.Equal(sum, w.pending) {
+		w.pending = sum
+		return nil
+	}
+	w.applied, w.pending = sum, nil
+
+	cfg, err := parseConfig(w.filename, data)
+	if err != nil {
+		return err
+	}
+	return w.logger.ApplyConfig(cfg)
+}
+
+func checksum(data []byte) []byte {
+	sum := sha256.Sum256(data)
+	return sum[:]
+}
+
+// Close stops watching, the logger keeps the last applied config.
+func (w *ConfigWatcher) Close() error {
+	close(w.done)
+	w.Wait()
+	return nil
+}
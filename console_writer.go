@@ -33,6 +33,13 @@ func NewConsoleWriter() LogWriter {
 	return w
 }
 
+// NewConsoleWriterTo creates a ConsoleWriter writing to w, such as os.Stderr.
+func NewConsoleWriterTo(w io.Writer) LogWriter {
+	return &ConsoleWriter{
+		writer: w,
+	}
+}
+
 func (cw *ConsoleWriter) Write(formatLog []byte) error {
 	_, err := cw.writer.Write(formatLog)
 	return err
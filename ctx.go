@@ -35,3 +35,22 @@ func GetLogIDFromCtx(ctx context.Context) string {
 
 	return logID
 }
+
+type loggerKey struct{}
+
+// NewContext returns a copy of ctx carrying logger, the package-level Ctx
+// functions log to it instead of the default logger.
+func NewContext(ctx context.Context, logger *Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, logger)
+}
+
+// FromContext returns the logger carried by ctx, or the default logger if
+// there is none.
+func FromContext(ctx context.Context) *Logger {
+	if ctx != nil {
+		if logger, ok := ctx.Value(loggerKey{}).(*Logger); ok && logger != nil {
+			return logger
+		}
+	}
+	return GetLogger()
+}
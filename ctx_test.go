@@ -33,3 +33,19 @@ func TestInjectLogIDToCtx(t *testing.T) {
 	// empty
 	assert.Equal(t, "-", GetLogIDFromCtx(context.Background()))
 }
+
+func TestLoggerContext(t *testing.T) {
+	assert.Equal(t, GetLogger(), FromContext(context.Background()))
+
+	logger := NewLogger(new(BufferWriter))
+	logger.SetFormatter(NewTextFormatter(false))
+	logger.SetCaller(false)
+	logger.SetLevel(WARN)
+	ctx := InjectLogIDToCtx(NewContext(context.Background(), logger), "1234567890")
+	assert.Equal(t, logger, FromContext(ctx))
+
+	CtxInfo(ctx, "dropped %d", 1)
+	assert.False(t, Enabled(ctx, INFO))
+	CtxWarnMsg(ctx, "request scoped")
+	assert.Equal(t, "WARN 1234567890 "+GetLocalIP()+" request scoped\n", logger.GetWriter().(*BufferWriter).String())
+}
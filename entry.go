@@ -0,0 +1,61 @@
+/*
+ * Copyright 2022 ByteDance and/or its affiliates.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dyclog
+
+import (
+	"context"
+	"runtime"
+	"time"
+)
+
+// Entry is a single log record passed from Logger to Processors and Formatter.
+type Entry struct {
+	Time    time.Time
+	Level   Level
+	Message string
+	Context context.Context
+	Caller  *runtime.Frame
+	Fields  []Field
+}
+
+// Field is an extra key-value pair attached to an Entry.
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+// AddField appends a key-value pair to the entry.
+func (e *Entry) AddField(key string, value interface{}) {
+	e.Fields = append(e.Fields, Field{Key: key, Value: value})
+}
+
+func (e *Entry) clone() *Entry {
+	c := *e
+	c.Fields = append([]Field(nil), e.Fields...)
+	return &c
+}
+
+func (e *Entry) reset() {
+	e.Context = nil
+	e.Caller = nil
+	e.Message = ""
+	// zero the fields so the pooled backing array does not keep their values alive.
+	for i := range e.Fields {
+		e.Fields[i] = Field{}
+	}
+	e.Fields = e.Fields[:0]
+}
@@ -0,0 +1,122 @@
+/*
+ * Copyright 2022 ByteDance and/or its affiliates.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dyclog
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Environment variables read by NewLoggerFromEnv.
+const (
+	EnvLevel    = "DYCLOG_LEVEL"
+	EnvFormat   = "DYCLOG_FORMAT"
+	EnvOutput   = "DYCLOG_OUTPUT"
+	EnvAsync    = "DYCLOG_ASYNC"
+	EnvColor    = "DYCLOG_COLOR"
+	EnvSampling = "DYCLOG_SAMPLING"
+)
+
+// NewLoggerFromEnv creates a logger like NewDefaultLogger, overridden by
+// the DYCLOG_* environment variables:
+//
+//	DYCLOG_LEVEL     level name or number, e.g. info
+//	DYCLOG_FORMAT    text, json or logfmt
+//	DYCLOG_OUTPUT    stdout, stderr or a file path rotated daily
+//	DYCLOG_ASYNC     whether to write asynchronously, true by default
+//	DYCLOG_COLOR     whether the text format is colored
+//	DYCLOG_SAMPLING  rate in (0, 1] of entries less severe than ERROR to keep
+//
+// Invalid values are reported to stderr and the default is used instead.
+func NewLoggerFromEnv() *Logger {
+	return newLoggerFromEnv(os.Getenv, os.Stderr)
+}
+
+func newLoggerFromEnv(getenv func(string) string, stderr io.Writer) *Logger {
+	invalid := func(key, value string, err error) {
+		_, _ = fmt.Fprintf(stderr, "dyclog: ignore invalid %s=%q: %s\n", key, value, err)
+	}
+	getBool := func(key string, def bool) bool {
+		value := getenv(key)
+		if value == "" {
+			return def
+		}
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			invalid(key, value, err)
+			return def
+		}
+		return b
+	}
+
+	logger := NewLogger(NewConsoleWriter())
+
+	if value := getenv(EnvLevel); value != "" {
+		level, err := ParseLevel(value)
+		if err != nil {
+			invalid(EnvLevel, value, err)
+		} else {
+			logger.SetLevel(level)
+		}
+	}
+
+	color := getBool(EnvColor, false)
+	switch value := strings.ToLower(getenv(EnvFormat)); value {
+	case "", "text":
+		logger.SetFormatter(NewTextFormatter(color))
+	case "json":
+		logger.SetFormatter(NewJSONFormatter())
+	case "logfmt":
+		logger.SetFormatter(NewLogfmtFormatter())
+	default:
+		invalid(EnvFormat, value, fmt.Errorf("unknown format"))
+		logger.SetFormatter(NewTextFormatter(color))
+	}
+
+	switch value := getenv(EnvOutput); value {
+	case "", "stdout":
+	case "stderr":
+		logger.SetWriter(NewConsoleWriterTo(os.Stderr))
+	default:
+		writer, err := OpenFileWriter(value, Daily)
+		if err != nil {
+			invalid(EnvOutput, value, err)
+		} else {
+			logger.SetWriter(writer)
+		}
+	}
+
+	if getBool(EnvAsync, true) {
+		logger.SetWriter(NewAsyncWriter(logger.GetWriter(), false))
+	}
+
+	if value := getenv(EnvSampling); value != "" {
+		rate, err := strconv.ParseFloat(value, 64)
+		if err == nil && (rate <= 0 || rate > 1) {
+			err = fmt.Errorf("rate must be in (0, 1]")
+		}
+		if err != nil {
+			invalid(EnvSampling, value, err)
+		} else {
+			logger.SetSampler(NewSampler(rate))
+		}
+	}
+	return logger
+}
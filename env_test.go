@@ -0,0 +1,85 @@
+/*
+ * Copyright 2022 ByteDance and/or its affiliates.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dyclog
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoggerFromEnv(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "dyc.log")
+
+	env := map[string]string{
+		EnvLevel:    "warn",
+		EnvFormat:   "json",
+		EnvOutput:   filename,
+		EnvAsync:    "false",
+		EnvSampling: "0.5",
+	}
+	stderr := new(bytes.Buffer)
+	logger := newLoggerFromEnv(func(key string) string { return env[key] }, stderr)
+	assert.Equal(t, "", stderr.String())
+	assert.Equal(t, WARN, logger.GetLevel())
+	assert.IsType(t, &JSONFormatter{}, logger.loadOutput().formatter)
+	assert.IsType(t, &FileWriter{}, logger.GetWriter())
+	assert.NotNil(t, logger.sampler)
+	assert.Nil(t, logger.Close())
+
+	env = map[string]string{
+		EnvLevel:    "loud",
+		EnvFormat:   "xml",
+		EnvOutput:   "stderr",
+		EnvAsync:    "maybe",
+		EnvColor:    "yes",
+		EnvSampling: "2",
+	}
+	stderr.Reset()
+	logger = newLoggerFromEnv(func(key string) string { return env[key] }, stderr)
+	assert.Equal(t, DEBUG, logger.GetLevel())
+	assert.IsType(t, &TextFormatter{}, logger.loadOutput().formatter)
+	assert.IsType(t, &AsyncWriter{}, logger.GetWriter())
+	assert.Nil(t, logger.sampler)
+	for _, key := range []string{EnvLevel, EnvFormat, EnvAsync, EnvColor, EnvSampling} {
+		assert.Contains(t, stderr.String(), "dyclog: ignore invalid "+key+"=")
+	}
+}
+
+func TestLoggerFromEnvLevel(t *testing.T) {
+	data := map[string]Level{
+		"bogus": DEBUG,
+		"1":     INFO,
+		"-1":    TRACE,
+		"99":    DEBUG,
+	}
+	for value, want := range data {
+		stderr := new(bytes.Buffer)
+		logger := newLoggerFromEnv(func(key string) string {
+			if key == EnvLevel {
+				return value
+			}
+			return ""
+		}, stderr)
+		assert.Equal(t, want, logger.GetLevel(), value)
+		assert.Equal(t, want == DEBUG, stderr.Len() > 0, value)
+		assert.Nil(t, logger.Close())
+	}
+}
@@ -16,86 +16,194 @@
 
 package dyclog
 
-import "context"
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync/atomic"
+)
 
-var defaultLogger *Logger
+// defaultLogger holds the *Logger used by the package-level functions.
+var defaultLogger atomic.Value
 
 func init() {
-	defaultLogger = NewDefaultLogger()
+	defaultLogger.Store(NewLoggerFromEnv())
 }
 
+// GetLogger returns the default logger.
 func GetLogger() *Logger {
-	return defaultLogger
+	return defaultLogger.Load().(*Logger)
+}
+
+// SetDefault replaces the default logger used by the package-level functions
+// and flushes the previous one, which is not closed. Entries being logged
+// while it is called may still go to the previous logger.
+func SetDefault(logger *Logger) {
+	if logger == nil {
+		return
+	}
+	old := defaultLogger.Swap(logger).(*Logger)
+	if err := old.Flush(); err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "log flush error: %s\n", err)
+	}
 }
 
 func SetWriter(writer LogWriter) {
-	defaultLogger.SetWriter(writer)
+	GetLogger().SetWriter(writer)
 }
 
 func SetFormatter(formatter Formatter) {
-	defaultLogger.SetFormatter(formatter)
+	GetLogger().SetFormatter(formatter)
 }
 
 func SetLevel(level Level) {
-	defaultLogger.SetLevel(level)
+	GetLogger().SetLevel(level)
+}
+
+func SetAtomicLevel(level *AtomicLevel) {
+	GetLogger().SetAtomicLevel(level)
 }
 
 func SetCallDepth(depth int) {
-	defaultLogger.SetCallDepth(depth)
+	GetLogger().SetCallDepth(depth)
+}
+
+func SetCaller(enable bool) {
+	GetLogger().SetCaller(enable)
+}
+
+func SetSampler(sampler Sampler) {
+	GetLogger().SetSampler(sampler)
+}
+
+func AddProcessor(processors ...Processor) {
+	GetLogger().AddProcessor(processors...)
+}
+
+func AddHook(hook Hook) {
+	GetLogger().AddHook(hook)
 }
 
 func Flush() error {
-	return defaultLogger.Flush()
+	return GetLogger().Flush()
 }
 
 func Close() error {
-	return defaultLogger.Close()
+	return GetLogger().Close()
+}
+
+func Enabled(ctx context.Context, level Level) bool {
+	return FromContext(ctx).Enabled(ctx, level)
+}
+
+func Trace(format string, args ...interface{}) {
+	GetLogger().Logf(context.Background(), TRACE, format, args...)
 }
 
 func Debug(format string, args ...interface{}) {
-	defaultLogger.Logf(context.Background(), DEBUG, format, args...)
+	GetLogger().Logf(context.Background(), DEBUG, format, args...)
 }
 
 func Info(format string, args ...interface{}) {
-	defaultLogger.Logf(context.Background(), INFO, format, args...)
+	GetLogger().Logf(context.Background(), INFO, format, args...)
 }
 
 func Notice(format string, args ...interface{}) {
-	defaultLogger.Logf(context.Background(), NOTICE, format, args...)
+	GetLogger().Logf(context.Background(), NOTICE, format, args...)
 }
 
 func Warn(format string, args ...interface{}) {
-	defaultLogger.Logf(context.Background(), WARN, format, args...)
+	GetLogger().Logf(context.Background(), WARN, format, args...)
 }
 
 func Error(format string, args ...interface{}) {
-	defaultLogger.Logf(context.Background(), ERROR, format, args...)
+	GetLogger().Logf(context.Background(), ERROR, format, args...)
 }
 
 func Fatal(format string, args ...interface{}) {
-	defaultLogger.Logf(context.Background(), FATAL, format, args...)
+	GetLogger().Logf(context.Background(), FATAL, format, args...)
+}
+
+func CtxTrace(ctx context.Context, format string, args ...interface{}) {
+	FromContext(ctx).Logf(ctx, TRACE, format, args...)
 }
 
 func CtxDebug(ctx context.Context, format string, args ...interface{}) {
-	defaultLogger.Logf(ctx, DEBUG, format, args...)
+	FromContext(ctx).Logf(ctx, DEBUG, format, args...)
 }
 
 func CtxInfo(ctx context.Context, format string, args ...interface{}) {
-	defaultLogger.Logf(ctx, INFO, format, args...)
+	FromContext(ctx).Logf(ctx, INFO, format, args...)
 }
 
 func CtxNotice(ctx context.Context, format string, args ...interface{}) {
-	defaultLogger.Logf(ctx, NOTICE, format, args...)
+	FromContext(ctx).Logf(ctx, NOTICE, format, args...)
 }
 
 func CtxWarn(ctx context.Context, format string, args ...interface{}) {
-	defaultLogger.Logf(ctx, WARN, format, args...)
+	FromContext(ctx).Logf(ctx, WARN, format, args...)
 }
 
 func CtxError(ctx context.Context, format string, args ...interface{}) {
-	defaultLogger.Logf(ctx, ERROR, format, args...)
+	FromContext(ctx).Logf(ctx, ERROR, format, args...)
 }
 
 func CtxFatal(ctx context.Context, format string, args ...interface{}) {
-	defaultLogger.Logf(ctx, FATAL, format, args...)
+	FromContext(ctx).Logf(ctx, FATAL, format, args...)
+}
+
+func TraceMsg(msg string) {
+	GetLogger().Log(context.Background(), TRACE, msg)
+}
+
+func DebugMsg(msg string) {
+	GetLogger().Log(context.Background(), DEBUG, msg)
+}
+
+func InfoMsg(msg string) {
+	GetLogger().Log(context.Background(), INFO, msg)
+}
+
+func NoticeMsg(msg string) {
+	GetLogger().Log(context.Background(), NOTICE, msg)
+}
+
+func WarnMsg(msg string) {
+	GetLogger().Log(context.Background(), WARN, msg)
+}
+
+func ErrorMsg(msg string) {
+	GetLogger().Log(context.Background(), ERROR, msg)
+}
+
+func FatalMsg(msg string) {
+	GetLogger().Log(context.Background(), FATAL, msg)
+}
+
+func CtxTraceMsg(ctx context.Context, msg string) {
+	FromContext(ctx).Log(ctx, TRACE, msg)
+}
+
+func CtxDebugMsg(ctx context.Context, msg string) {
+	FromContext(ctx).Log(ctx, DEBUG, msg)
+}
+
+func CtxInfoMsg(ctx context.Context, msg string) {
+	FromContext(ctx).Log(ctx, INFO, msg)
+}
+
+func CtxNoticeMsg(ctx context.Context, msg string) {
+	FromContext(ctx).Log(ctx, NOTICE, msg)
+}
+
+func CtxWarnMsg(ctx context.Context, msg string) {
+	FromContext(ctx).Log(ctx, WARN, msg)
+}
+
+func CtxErrorMsg(ctx context.Context, msg string) {
+	FromContext(ctx).Log(ctx, ERROR, msg)
+}
+
+func CtxFatalMsg(ctx context.Context, msg string) {
+	FromContext(ctx).Log(ctx, FATAL, msg)
 }
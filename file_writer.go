@@ -17,17 +17,24 @@
 package dyclog
 
 import (
+	"compress/gzip"
 	"fmt"
 	"io"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"sort"
 	"strings"
 	"sync"
+	"syscall"
 	"time"
 )
 
-const dateFormat = "2006-01-02_15"
+const (
+	dateFormat       = "2006-01-02_15"
+	minuteDateFormat = "2006-01-02_15-04"
+	compressSuffix   = ".gz"
+)
 
 // RotationWindow allows to claim which rotation window provider uses.
 type RotationWindow int8
@@ -37,114 +44,398 @@ const (
 	Daily RotationWindow = iota
 	// Hourly means rotate hourly.
 	Hourly
+	// Minutely means rotate every minute.
+	Minutely
 )
 
+// SyncPolicy tells when FileWriter commits written entries to disk with
+// fsync, so they are not lost if the machine crashes.
+type SyncPolicy int8
+
+const (
+	// SyncNever leaves syncing to the operating system.
+	SyncNever SyncPolicy = iota
+	// SyncPeriodically syncs every sync interval, see SetSyncInterval.
+	SyncPeriodically
+	// SyncOnError syncs after each entry of ERROR level or above.
+	SyncOnError
+	// SyncAlways syncs after each entry.
+	SyncAlways
+)
+
+// Clock tells FileWriter the time to rotate files on.
+type Clock interface {
+	Now() time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
 // FileWriter provides a file rotated output to loggers,
 // it is thread-safe and uses memory buffer to boost file writing performance.
 type FileWriter struct {
 	file           *rotatedFile
 	filename       string
+	dir            string
 	rotationWindow RotationWindow
-	fileCountLimit int
+	// rotationInterval overrides rotationWindow if set.
+	rotationInterval time.Duration
+	timeLayout       string
+	location         *time.Location
+	clock            Clock
+	filenamePattern  string
+	pattern          *filenamePattern
+	fileCountLimit   int
+	maxFileSize      int64
+	maxAge           time.Duration
+	maxTotalSize     int64
+	compress         bool
+	compressLevel    int
+	bufferSize       int
+	flushInterval    time.Duration
+	syncPolicy       SyncPolicy
+	syncInterval     time.Duration
+
+	// maintain wakes up the goroutine compressing and cleaning rotated files,
+	// it never blocks Write as pending wake-ups are merged.
+	maintain  chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+	closeErr  error
+	wg        sync.WaitGroup
+
+	// retryInterval enables the degraded mode, in which entries are written
+	// to fallback while the file cannot be opened, and opening it is retried
+	// at nextRetry.
+	retryInterval time.Duration
+	fallback      io.Writer
+	degraded      bool
+	nextRetry     time.Time
+
+	// the file is reopened on reopenSignals, or when currentName is not
+	// currentInfo anymore, checked every reopenInterval.
+	reopenSignals  []os.Signal
+	reopenInterval time.Duration
 
+	currentName string
+	currentInfo os.FileInfo
+	// currentTimeSeg is the start of the current time window, nextBoundary
+	// the start of the next one.
 	currentTimeSeg time.Time
+	nextBoundary   time.Time
+	// currentSize is the size of the current file, seq is its sequence
+	// number within the time window.
+	currentSize int64
+	seq         int
 	sync.RWMutex
 }
 
-// NewFileWriter creates a FileWriter.
+// NewFileWriter creates a FileWriter, it panics if the file cannot be opened.
 func NewFileWriter(filename string, window RotationWindow, options ...FileOption) LogWriter {
+	w, err := OpenFileWriter(filename, window, options...)
+	if err != nil {
+		panic(err)
+	}
+	return w
+}
+
+// OpenFileWriter creates a FileWriter, it returns an error if the file cannot
+// be opened unless SetDegradedMode is given.
+func OpenFileWriter(filename string, window RotationWindow, options ...FileOption) (*FileWriter, error) {
 	w := &FileWriter{
 		filename:       filename,
 		rotationWindow: window,
+		location:       time.Local,
+		clock:          systemClock{},
+		fallback:       os.Stderr,
+		bufferSize:     defaultFlushSize,
+		flushInterval:  defaultFlushInterval,
+		syncInterval:   defaultSyncInterval,
+		maintain:       make(chan struct{}, 1),
+		done:           make(chan struct{}),
 	}
-	file, err := w.loadFile()
-	if err != nil {
-		panic(err)
-	}
-	w.file = newRotatedFile(file)
 	for _, op := range options {
 		op(w)
 	}
-	return w
+	if err := w.init(); err != nil {
+		return nil, err
+	}
+	file, err := w.loadFile(true)
+	if err != nil {
+		if w.retryInterval <= 0 {
+			return nil, err
+		}
+		file = w.degrade(err)
+	}
+	var syncInterval time.Duration
+	if w.syncPolicy == SyncPeriodically {
+		syncInterval = w.syncInterval
+	}
+	w.file = newRotatedFile(file, w.bufferSize, w.flushInterval, syncInterval, w.syncPolicy != SyncNever)
+	w.wg.Add(1)
+	go w.maintainFiles()
+	w.triggerMaintain()
+	if len(w.reopenSignals) > 0 {
+		sigs := make(chan os.Signal, 1)
+		signal.Notify(sigs, w.reopenSignals...)
+		w.wg.Add(1)
+		go w.reopenOnSignal(sigs)
+	}
+	if w.reopenInterval > 0 {
+		w.wg.Add(1)
+		go w.reopenOnChange()
+	}
+	return w, nil
 }
 
-func (w *FileWriter) loadFile() (io.WriteCloser, error) {
-	timedName, currentTimeSeg, err := timedFilename(w.filename)
+func (w *FileWriter) init() error {
+	absPath, err := filepath.Abs(w.filename)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	err = os.MkdirAll(filepath.Dir(timedName), os.ModeDir|os.ModePerm)
-	if err != nil {
-		return nil, err
+	w.dir = filepath.Dir(absPath)
+	if d := w.rotationInterval; d != 0 && (d < time.Minute || d%time.Minute != 0 || (24*time.Hour)%d != 0) {
+		return fmt.Errorf("rotation interval %s must be a whole number of minutes dividing a day", d)
+	}
+	if w.timeLayout == "" {
+		w.timeLayout = dateFormat
+		if w.interval() < time.Hour {
+			w.timeLayout = minuteDateFormat
+		}
+	}
+	if w.filenamePattern == "" {
+		w.filenamePattern = DefaultFilenamePattern
 	}
-	file, err := os.OpenFile(timedName, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	w.pattern, err = newFilenamePattern(w.filenamePattern, w.filename, w.timeLayout, w.location)
+	return err
+}
+
+// interval returns the length of the rotation windows.
+func (w *FileWriter) interval() time.Duration {
+	if w.rotationInterval > 0 {
+		return w.rotationInterval
+	}
+	switch w.rotationWindow {
+	case Hourly:
+		return time.Hour
+	case Minutely:
+		return time.Minute
+	}
+	return 24 * time.Hour
+}
+
+// window returns the start of the rotation window containing t and the
+// start of the next one. Windows are aligned to midnight on the wall clock
+// of the location, so they keep their local times across DST changes.
+func (w *FileWriter) window(t time.Time) (start, next time.Time) {
+	t = t.In(w.location)
+	y, m, d := t.Date()
+	interval := w.interval()
+	if interval >= 24*time.Hour {
+		return time.Date(y, m, d, 0, 0, 0, 0, w.location), time.Date(y, m, d+1, 0, 0, 0, 0, w.location)
+	}
+	minutes := int(interval / time.Minute)
+	offset := (t.Hour()*60 + t.Minute()) / minutes * minutes
+	start = time.Date(y, m, d, 0, offset, 0, 0, w.location)
+	next = time.Date(y, m, d, 0, offset+minutes, 0, 0, w.location)
+	// wall times repeated when clocks go back may resolve before t
+	for !next.After(t) {
+		next = next.Add(interval)
+	}
+	return start, next
+}
+
+// segmentFilename returns the path of the seq-th file of the window starting at date.
+func (w *FileWriter) segmentFilename(date time.Time, seq int) string {
+	return filepath.Join(w.dir, w.pattern.format(date, seq))
+}
+
+// loadFile opens the file of the current time window, recoverSeq finds the
+// last sequence number of the window, as after a restart or a time rotation.
+func (w *FileWriter) loadFile(recoverSeq bool) (io.WriteCloser, error) {
+	currentTimeSeg, nextBoundary := w.window(w.clock.Now())
+	err := os.MkdirAll(w.dir, os.ModeDir|os.ModePerm)
 	if err != nil {
 		return nil, err
 	}
+	if recoverSeq {
+		w.seq = w.lastFileSeq(currentTimeSeg)
+	}
+	var file *os.File
+	for {
+		file, err = os.OpenFile(w.segmentFilename(currentTimeSeg, w.seq), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+		if err != nil {
+			return nil, err
+		}
+		info, err := file.Stat()
+		if err != nil {
+			_ = file.Close()
+			return nil, err
+		}
+		w.currentSize = info.Size()
+		w.currentInfo = info
+		if w.maxFileSize <= 0 || w.currentSize < w.maxFileSize {
+			break
+		}
+		_ = file.Close()
+		w.seq++
+	}
 	if _, err := os.Lstat(w.filename); err == nil {
 		_ = os.Remove(w.filename)
 	}
-	_ = os.Symlink(filepath.Base(timedName), w.filename)
+	_ = os.Symlink(filepath.Base(file.Name()), w.filename)
+	w.currentName = file.Name()
 	w.currentTimeSeg = currentTimeSeg
+	w.nextBoundary = nextBoundary
 	return file, nil
 }
 
-func (w *FileWriter) checkIfNeedRotate(logTime time.Time) error {
-	var needRotate bool
+func (w *FileWriter) checkIfNeedRotate(now time.Time, size int) error {
+	// opening a file failed, it is not retried before nextRetry
+	if now.Before(w.nextRetry) {
+		return nil
+	}
+	needRotate := w.degraded || !now.Before(w.nextBoundary)
 
-	switch w.rotationWindow {
-	case Daily:
-		if w.currentTimeSeg.YearDay() != logTime.YearDay() {
-			needRotate = true
-		}
-	case Hourly:
-		if w.currentTimeSeg.Hour() != logTime.Hour() || w.currentTimeSeg.YearDay() != logTime.YearDay() {
-			needRotate = true
-		}
+	recoverSeq, seq := needRotate, w.seq
+	if !needRotate && w.maxFileSize > 0 && w.currentSize > 0 && w.currentSize+int64(size) > w.maxFileSize {
+		w.seq++
+		needRotate = true
 	}
 
 	if needRotate {
-		defer func() {
-			go w.cleanFiles(w.fileCountLimit)
-		}()
-		if err := w.rotate(); err != nil {
+		defer w.triggerMaintain()
+		if err := w.rotate(recoverSeq); err != nil {
+			w.seq = seq
+			if w.retryInterval > 0 {
+				w.nextRetry = now.Add(w.retryInterval)
+			}
 			return err
 		}
+		w.degraded = false
 	}
 	return nil
 }
 
-func getFileDate(name string) time.Time {
-	sn := strings.Split(name, ".")
-	t, _ := time.Parse(dateFormat, sn[len(sn)-1])
-	return t
+// degrade makes the writer write to its fallback until the file is opened.
+func (w *FileWriter) degrade(err error) io.WriteCloser {
+	_, _ = fmt.Fprintf(os.Stderr, "open file %s error: %s, retry in %s\n", w.filename, err, w.retryInterval)
+	w.degraded = true
+	w.nextRetry = w.clock.Now().Add(w.retryInterval)
+	return nopWriteCloser{w.fallback}
 }
 
-func (w *FileWriter) cleanFiles(limit int) {
-	if limit <= 0 {
-		return
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error {
+	return nil
+}
+
+// lastFileSeq returns the largest sequence number of the existing files of
+// the window starting at date.
+func (w *FileWriter) lastFileSeq(date time.Time) int {
+	entries, err := os.ReadDir(w.dir)
+	if err != nil {
+		return 0
 	}
-	logs := make([]string, 0)
-	_ = filepath.Walk(filepath.Dir(w.filename), func(path string, info os.FileInfo, err error) error {
-		if strings.HasPrefix(path, w.filename+".") {
-			logs = append(logs, path)
+	want := date.Format(w.timeLayout)
+	last, compressed := 0, false
+	for _, entry := range entries {
+		d, seq, ok := w.pattern.parse(entry.Name())
+		if !ok || d.Format(w.timeLayout) != want {
+			continue
+		}
+		gz := strings.HasSuffix(entry.Name(), compressSuffix)
+		if seq > last {
+			last, compressed = seq, gz
+		} else if seq == last {
+			compressed = compressed || gz
 		}
+	}
+	// a compressed file is never appended
+	if compressed {
+		last++
+	}
+	return last
+}
+
+// rotatedLog is a file written by a FileWriter before the current one.
+type rotatedLog struct {
+	path string
+	date time.Time
+	seq  int
+	size int64
+}
+
+// rotatedLogs lists the rotated files of w, newest first.
+func (w *FileWriter) rotatedLogs() []rotatedLog {
+	w.RLock()
+	current := w.currentName
+	w.RUnlock()
+
+	entries, err := os.ReadDir(w.dir)
+	if err != nil {
 		return nil
+	}
+	logs := make([]rotatedLog, 0, len(entries))
+	for _, entry := range entries {
+		path := filepath.Join(w.dir, entry.Name())
+		if !entry.Type().IsRegular() || path == current {
+			continue
+		}
+		date, seq, ok := w.pattern.parse(entry.Name())
+		if !ok {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		logs = append(logs, rotatedLog{path: path, date: date, seq: seq, size: info.Size()})
+	}
+	sort.Slice(logs, func(i, j int) bool {
+		if logs[i].date.Equal(logs[j].date) {
+			return logs[i].seq > logs[j].seq
+		}
+		return logs[i].date.After(logs[j].date)
 	})
+	return logs
+}
 
-	if len(logs) <= limit {
+// cleanFiles removes the oldest rotated files beyond the limit of files,
+// those older than the max age by the time in their names, and the oldest
+// ones until all files including the current one fit the max total size.
+func (w *FileWriter) cleanFiles() {
+	if w.fileCountLimit <= 0 && w.maxAge <= 0 && w.maxTotalSize <= 0 {
 		return
 	}
-	sort.Slice(logs, func(i, j int) bool {
-		return getFileDate(logs[i]).After(getFileDate(logs[j]))
-	})
-	for _, f := range logs[limit:] {
-		_ = os.Remove(f)
+	logs := w.rotatedLogs()
+	w.RLock()
+	total := w.currentSize
+	w.RUnlock()
+
+	files, now, full := 1, w.clock.Now(), false
+	for _, log := range logs {
+		full = full ||
+			w.fileCountLimit > 0 && files >= w.fileCountLimit ||
+			w.maxAge > 0 && now.Sub(log.date) > w.maxAge ||
+			w.maxTotalSize > 0 && total+log.size > w.maxTotalSize
+		if full {
+			_ = os.Remove(log.path)
+			continue
+		}
+		files++
+		total += log.size
 	}
 }
 
-func (w *FileWriter) rotate() error {
-	file, err := w.loadFile()
+func (w *FileWriter) rotate(recoverSeq bool) error {
+	file, err := w.loadFile(recoverSeq)
 	if err != nil {
 		return err
 	}
@@ -153,36 +444,177 @@ func (w *FileWriter) rotate() error {
 }
 
 func (w *FileWriter) Write(formatLog []byte) error {
-	w.Lock()
-	err := w.checkIfNeedRotate(time.Now())
-	w.Unlock()
-	if err != nil {
-		_, _ = fmt.Fprintf(os.Stderr, "write file %s error: %s\n", w.filename, err)
+	return w.WriteLevel(InvalidLevel, formatLog)
+}
+
+// WriteLevel writes an entry of level, it is synced to disk before WriteLevel
+// returns with SyncAlways, or with SyncOnError if level is ERROR or above.
+func (w *FileWriter) WriteLevel(level Level, formatLog []byte) error {
+	size := len(formatLog)
+	if size == 0 || formatLog[size-1] != '\n' {
+		size++
 	}
 
+	w.Lock()
+	defer w.Unlock()
+	err := w.checkIfNeedRotate(w.clock.Now(), size)
 	if err != nil {
 		_, _ = fmt.Fprintf(os.Stderr, "write file %s error: %s\n", w.filename, err)
 	}
 	_, err = w.file.Write(formatLog)
+	w.currentSize += int64(size)
+	if err == nil && (w.syncPolicy == SyncAlways || w.syncPolicy == SyncOnError && level >= ERROR) {
+		err = w.file.Sync()
+	}
 	return err
 }
 
+// triggerMaintain wakes up maintainFiles without blocking.
+func (w *FileWriter) triggerMaintain() {
+	select {
+	case w.maintain <- struct{}{}:
+	default:
+	}
+}
+
+// maintainFiles compresses and cleans rotated files in the background.
+func (w *FileWriter) maintainFiles() {
+	defer w.wg.Done()
+	for {
+		select {
+		case <-w.done:
+			return
+		case <-w.maintain:
+			if w.compress {
+				w.compressFiles()
+			}
+			w.cleanFiles()
+		}
+	}
+}
+
+// compressFiles gzips the rotated files which are not compressed yet.
+func (w *FileWriter) compressFiles() {
+	for _, log := range w.rotatedLogs() {
+		if strings.HasSuffix(log.path, compressSuffix) {
+			continue
+		}
+		select {
+		case <-w.done:
+			return
+		default:
+		}
+		if err := compressFile(log.path, w.compressLevel); err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "compress file %s error: %s\n", log.path, err)
+		}
+	}
+}
+
+// compressFile replaces name with name.gz.
+func compressFile(name string, level int) (err error) {
+	src, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	dst, err := os.OpenFile(name+compressSuffix, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = dst.Close()
+			_ = os.Remove(dst.Name())
+		}
+	}()
+	zw, err := gzip.NewWriterLevel(dst, level)
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(zw, src); err != nil {
+		return err
+	}
+	if err = zw.Close(); err != nil {
+		return err
+	}
+	if err = dst.Close(); err != nil {
+		return err
+	}
+	return os.Remove(name)
+}
+
+// Reopen closes the current file and opens the file of the current window
+// by its name again, call it after the file is moved or deleted, e.g. by
+// logrotate, so entries are not written to an unlinked file.
+func (w *FileWriter) Reopen() error {
+	w.Lock()
+	defer w.Unlock()
+	if err := w.rotate(true); err != nil {
+		return err
+	}
+	w.degraded = false
+	return nil
+}
+
+func (w *FileWriter) reopenOnSignal(sigs chan os.Signal) {
+	defer w.wg.Done()
+	defer signal.Stop(sigs)
+	for {
+		select {
+		case <-w.done:
+			return
+		case <-sigs:
+			if err := w.Reopen(); err != nil {
+				_, _ = fmt.Fprintf(os.Stderr, "reopen file %s error: %s\n", w.filename, err)
+			}
+		}
+	}
+}
+
+// reopenOnChange reopens the file once its name refers to another file or
+// to none.
+func (w *FileWriter) reopenOnChange() {
+	defer w.wg.Done()
+	ticker := time.NewTicker(w.reopenInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-w.done:
+			return
+		case <-ticker.C:
+			w.RLock()
+			name, info, degraded := w.currentName, w.currentInfo, w.degraded
+			w.RUnlock()
+			if degraded || info == nil {
+				continue
+			}
+			if current, err := os.Stat(name); err == nil && os.SameFile(info, current) {
+				continue
+			}
+			if err := w.Reopen(); err != nil {
+				_, _ = fmt.Fprintf(os.Stderr, "reopen file %s error: %s\n", w.filename, err)
+			}
+		}
+	}
+}
+
+// Close flushes and closes the current file, it can be called more than once.
 func (w *FileWriter) Close() error {
-	return w.file.Close()
+	w.closeOnce.Do(func() {
+		close(w.done)
+		w.wg.Wait()
+		w.closeErr = w.file.Close()
+	})
+	return w.closeErr
 }
 
 func (w *FileWriter) Flush() error {
 	return w.file.Flush()
 }
 
-func timedFilename(filename string) (string, time.Time, error) {
-	var now time.Time
-	absPath, err := filepath.Abs(filename)
-	if err != nil {
-		return "", now, err
-	}
-	now = time.Now()
-	return absPath + "." + now.Format(dateFormat), now, nil
+// Sync flushes the buffer and commits the current file to disk.
+func (w *FileWriter) Sync() error {
+	return w.file.Sync()
 }
 
 type FileOption func(writer *FileWriter)
@@ -192,3 +624,153 @@ func SetLimitFiles(n int) FileOption {
 		writer.fileCountLimit = n
 	}
 }
+
+// SetRotationInterval rotates files every d instead of the window given to
+// NewFileWriter, e.g. 6*time.Hour or 15*time.Minute. Windows are aligned to
+// midnight, so d must be a whole number of minutes dividing a day, other
+// values make OpenFileWriter fail.
+func SetRotationInterval(d time.Duration) FileOption {
+	return func(writer *FileWriter) {
+		writer.rotationInterval = d
+	}
+}
+
+// SetBufferSize sets the size entries are buffered up to before they are
+// written to the file, 4096 bytes by default, 0 writes every entry at once.
+func SetBufferSize(bytes int) FileOption {
+	return func(writer *FileWriter) {
+		if bytes >= 0 {
+			writer.bufferSize = bytes
+		}
+	}
+}
+
+// SetFlushInterval sets how often buffered entries are written to the file,
+// 5 seconds by default.
+func SetFlushInterval(d time.Duration) FileOption {
+	return func(writer *FileWriter) {
+		if d > 0 {
+			writer.flushInterval = d
+		}
+	}
+}
+
+// SetSyncPolicy sets when entries are committed to disk, SyncNever by
+// default. Files are also synced before they are closed unless it is SyncNever.
+func SetSyncPolicy(policy SyncPolicy) FileOption {
+	return func(writer *FileWriter) {
+		writer.syncPolicy = policy
+	}
+}
+
+// SetSyncInterval sets how often SyncPeriodically syncs, 1 second by default.
+func SetSyncInterval(d time.Duration) FileOption {
+	return func(writer *FileWriter) {
+		if d > 0 {
+			writer.syncInterval = d
+		}
+	}
+}
+
+// SetDegradedMode makes the writer write to stderr instead of failing when
+// the file cannot be opened, and retry opening it every retry.
+func SetDegradedMode(retry time.Duration) FileOption {
+	return func(writer *FileWriter) {
+		writer.retryInterval = retry
+	}
+}
+
+// SetReopenSignals reopens the file when the process receives one of sigs,
+// SIGHUP if none is given, see FileWriter.Reopen.
+func SetReopenSignals(sigs ...os.Signal) FileOption {
+	return func(writer *FileWriter) {
+		if len(sigs) == 0 {
+			sigs = []os.Signal{syscall.SIGHUP}
+		}
+		writer.reopenSignals = sigs
+	}
+}
+
+// SetReopenCheck checks every interval if the file was moved or deleted and
+// reopens it then, see FileWriter.Reopen.
+func SetReopenCheck(interval time.Duration) FileOption {
+	return func(writer *FileWriter) {
+		writer.reopenInterval = interval
+	}
+}
+
+// SetLocation sets the time zone of rotation windows and of the dates in
+// rotated file names, it defaults to time.Local.
+func SetLocation(loc *time.Location) FileOption {
+	return func(writer *FileWriter) {
+		if loc != nil {
+			writer.location = loc
+		}
+	}
+}
+
+// SetClock makes the writer read the time from clock, e.g. to test rotation.
+func SetClock(clock Clock) FileOption {
+	return func(writer *FileWriter) {
+		if clock != nil {
+			writer.clock = clock
+		}
+	}
+}
+
+// SetTimeLayout sets the time.Format layout of {date} in rotated file names,
+// it defaults to 2006-01-02_15, or 2006-01-02_15-04 for windows shorter than
+// an hour. It must not contain a path separator.
+func SetTimeLayout(layout string) FileOption {
+	return func(writer *FileWriter) {
+		writer.timeLayout = layout
+	}
+}
+
+// SetFilenamePattern sets the template of rotated file names, it defaults to
+// DefaultFilenamePattern. {name} is the base name of the log file, {base} the
+// name without extension and {ext} the extension, {date} is the start of the
+// time window and {seq} the sequence number of the file within the window,
+// which is omitted with the '.', '-' or '_' before it for the first file.
+// For example {base}-{date}.{seq}{ext} gives dyc-2006-01-02_15.log and
+// dyc-2006-01-02_15.1.log.
+func SetFilenamePattern(pattern string) FileOption {
+	return func(writer *FileWriter) {
+		writer.filenamePattern = pattern
+	}
+}
+
+// SetMaxAge removes rotated files whose time window started more than d ago.
+func SetMaxAge(d time.Duration) FileOption {
+	return func(writer *FileWriter) {
+		writer.maxAge = d
+	}
+}
+
+// SetMaxTotalSize removes the oldest rotated files until the size of all
+// files, including the current one, is at most bytes.
+func SetMaxTotalSize(bytes int64) FileOption {
+	return func(writer *FileWriter) {
+		writer.maxTotalSize = bytes
+	}
+}
+
+// SetCompress gzips rotated files to name.<date>.gz in the background with
+// level, one of the compress/gzip levels.
+func SetCompress(level int) FileOption {
+	return func(writer *FileWriter) {
+		if level < gzip.HuffmanOnly || level > gzip.BestCompression {
+			level = gzip.DefaultCompression
+		}
+		writer.compress = true
+		writer.compressLevel = level
+	}
+}
+
+// SetMaxFileSize rolls the file over to name.<date>.<seq> once it would
+// exceed bytes, within the rotation window. Zero means no size limit.
+func SetMaxFileSize(bytes int64) FileOption {
+	return func(writer *FileWriter) {
+		writer.maxFileSize = bytes
+	}
+}
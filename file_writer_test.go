@@ -0,0 +1,473 @@
+/*
+ * Copyright 2022 ByteDance and/or its affiliates.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dyclog
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileWriterMaxFileSize(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "app.log")
+	line := []byte(strings.Repeat("x", 29) + "\n")
+
+	w := NewFileWriter(filename, Daily, SetMaxFileSize(100))
+	for i := 0; i < 7; i++ {
+		assert.Nil(t, w.Write(line))
+	}
+	assert.Nil(t, w.Close())
+
+	fw := w.(*FileWriter)
+	date := fw.currentTimeSeg
+	assert.Equal(t, 2, fw.lastFileSeq(date))
+	for seq, lines := range []int{3, 3, 1} {
+		data, err := ioutil.ReadFile(fw.segmentFilename(date, seq))
+		assert.Nil(t, err)
+		assert.Equal(t, lines*len(line), len(data))
+	}
+
+	// after a restart the last file is appended until it is full
+	w = NewFileWriter(filename, Daily, SetMaxFileSize(100))
+	for i := 0; i < 3; i++ {
+		assert.Nil(t, w.Write(line))
+	}
+	assert.Nil(t, w.Close())
+	assert.Equal(t, 3, fw.lastFileSeq(date))
+	data, err := ioutil.ReadFile(fw.segmentFilename(date, 2))
+	assert.Nil(t, err)
+	assert.Equal(t, 3*len(line), len(data))
+
+	target, err := os.Readlink(filename)
+	assert.Nil(t, err)
+	assert.Equal(t, filepath.Base(fw.segmentFilename(date, 3)), target)
+}
+
+func TestFileWriterCompress(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "app.log")
+	line := []byte(strings.Repeat("x", 29) + "\n")
+
+	w := NewFileWriter(filename, Daily, SetMaxFileSize(100), SetCompress(gzip.BestSpeed))
+	for i := 0; i < 7; i++ {
+		assert.Nil(t, w.Write(line))
+	}
+	fw := w.(*FileWriter)
+	date := fw.currentTimeSeg
+	assert.Eventually(t, func() bool {
+		_, err0 := os.Stat(fw.segmentFilename(date, 0) + ".gz")
+		_, err1 := os.Stat(fw.segmentFilename(date, 1) + ".gz")
+		return err0 == nil && err1 == nil
+	}, time.Second, 10*time.Millisecond)
+	assert.Nil(t, w.Close())
+
+	_, err := os.Stat(fw.segmentFilename(date, 0))
+	assert.True(t, os.IsNotExist(err))
+	f, err := os.Open(fw.segmentFilename(date, 0) + ".gz")
+	assert.Nil(t, err)
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	assert.Nil(t, err)
+	data, err := ioutil.ReadAll(zr)
+	assert.Nil(t, err)
+	assert.Equal(t, strings.Repeat(string(line), 3), string(data))
+
+	// the current file is never compressed
+	_, err = os.Stat(fw.segmentFilename(date, 2))
+	assert.Nil(t, err)
+	assert.Equal(t, 2, fw.lastFileSeq(date))
+	assert.Nil(t, os.Rename(fw.segmentFilename(date, 2), fw.segmentFilename(date, 2)+".gz"))
+	assert.Equal(t, 3, fw.lastFileSeq(date))
+}
+
+func TestFileWriterRetention(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "app.log")
+	assert.Nil(t, os.MkdirAll(filepath.Join(dir, "sub"), os.ModePerm))
+
+	now := time.Now()
+	create := func(name string, size int) string {
+		path := filepath.Join(dir, name)
+		assert.Nil(t, ioutil.WriteFile(path, make([]byte, size), 0644))
+		return path
+	}
+	old := create("app.log."+now.Add(-10*24*time.Hour).Format(dateFormat)+".gz", 10)
+	big := create("app.log."+now.Add(-3*time.Hour).Format(dateFormat), 100)
+	recent := create("app.log."+now.Add(-2*time.Hour).Format(dateFormat)+".1", 40)
+	latest := create("app.log."+now.Add(-time.Hour).Format(dateFormat), 40)
+	other := create("app.log.bak", 1000)
+	nested := filepath.Join(dir, "sub", "app.log."+now.Add(-10*24*time.Hour).Format(dateFormat))
+	assert.Nil(t, ioutil.WriteFile(nested, nil, 0644))
+
+	w := NewFileWriter(filename, Hourly, SetMaxAge(7*24*time.Hour), SetMaxTotalSize(100))
+	exists := func(path string) bool {
+		_, err := os.Stat(path)
+		return err == nil
+	}
+	assert.Eventually(t, func() bool {
+		return !exists(old) && !exists(big)
+	}, time.Second, 10*time.Millisecond)
+	assert.Nil(t, w.Close())
+	assert.True(t, exists(recent))
+	assert.True(t, exists(latest))
+	assert.True(t, exists(other))
+	assert.True(t, exists(nested))
+}
+
+type fakeClock struct {
+	sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.Lock()
+	defer c.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Set(now time.Time) {
+	c.Lock()
+	c.now = now
+	c.Unlock()
+}
+
+func TestFileWriterWindow(t *testing.T) {
+	w := &FileWriter{rotationWindow: Hourly, rotationInterval: 6 * time.Hour, location: time.UTC}
+	now := time.Date(2022, 3, 4, 13, 45, 10, 0, time.UTC)
+	start, next := w.window(now)
+	assert.Equal(t, time.Date(2022, 3, 4, 12, 0, 0, 0, time.UTC), start)
+	assert.Equal(t, time.Date(2022, 3, 4, 18, 0, 0, 0, time.UTC), next)
+
+	w = &FileWriter{rotationWindow: Minutely, rotationInterval: 15 * time.Minute, location: time.UTC}
+	start, next = w.window(now)
+	assert.Equal(t, time.Date(2022, 3, 4, 13, 45, 0, 0, time.UTC), start)
+	assert.Equal(t, time.Date(2022, 3, 4, 14, 0, 0, 0, time.UTC), next)
+
+	w = &FileWriter{rotationWindow: Daily, location: time.UTC}
+	start, next = w.window(time.Date(2022, 12, 31, 23, 59, 59, 0, time.UTC))
+	assert.Equal(t, time.Date(2022, 12, 31, 0, 0, 0, 0, time.UTC), start)
+	assert.Equal(t, time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC), next)
+}
+
+func TestFileWriterWindowDST(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip(err)
+	}
+	// clocks go forward from 2:00 to 3:00 on 2022-03-13
+	w := &FileWriter{rotationWindow: Daily, location: loc}
+	start, next := w.window(time.Date(2022, 3, 13, 12, 0, 0, 0, loc))
+	assert.Equal(t, time.Date(2022, 3, 13, 0, 0, 0, 0, loc), start)
+	assert.Equal(t, time.Date(2022, 3, 14, 0, 0, 0, 0, loc), next)
+	assert.Equal(t, 23*time.Hour, next.Sub(start))
+
+	w = &FileWriter{rotationWindow: Hourly, rotationInterval: 6 * time.Hour, location: loc}
+	start, next = w.window(time.Date(2022, 3, 13, 7, 0, 0, 0, loc))
+	assert.Equal(t, 6, start.Hour())
+	assert.Equal(t, 12, next.Hour())
+
+	// clocks go back from 2:00 to 1:00 on 2022-11-06, 1:30 happens twice
+	w = &FileWriter{rotationWindow: Minutely, rotationInterval: 30 * time.Minute, location: loc}
+	second := time.Date(2022, 11, 6, 6, 10, 0, 0, time.UTC)
+	assert.Equal(t, 1, second.In(loc).Hour())
+	_, next = w.window(second)
+	assert.Equal(t, time.Date(2022, 11, 6, 6, 30, 0, 0, time.UTC), next.UTC())
+}
+
+func TestFileWriterRotateOnBoundary(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "app.log")
+
+	clock := &fakeClock{now: time.Date(2022, 12, 31, 23, 59, 59, 0, time.UTC)}
+	w := NewFileWriter(filename, Daily, SetClock(clock), SetLocation(time.UTC), SetTimeLayout("2006-01-02"))
+	assert.Nil(t, w.Write([]byte("last of 2022")))
+	clock.Set(time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC))
+	assert.Nil(t, w.Write([]byte("first of 2023")))
+	assert.Nil(t, w.Close())
+
+	data, err := ioutil.ReadFile(filepath.Join(dir, "app.log.2022-12-31"))
+	assert.Nil(t, err)
+	assert.Equal(t, "last of 2022\n", string(data))
+	data, err = ioutil.ReadFile(filepath.Join(dir, "app.log.2023-01-01"))
+	assert.Nil(t, err)
+	assert.Equal(t, "first of 2023\n", string(data))
+}
+
+func TestFileWriterFilenamePattern(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "app.log")
+
+	w := NewFileWriter(filename, Minutely, SetFilenamePattern("{base}-{date}.{seq}{ext}"), SetMaxFileSize(10))
+	assert.Nil(t, w.Write([]byte("first line")))
+	assert.Nil(t, w.Write([]byte("second line")))
+	fw := w.(*FileWriter)
+	date := fw.currentTimeSeg
+	assert.Nil(t, w.Close())
+
+	assert.Equal(t, filepath.Join(dir, "app-"+date.Format(minuteDateFormat)+".log"), fw.segmentFilename(date, 0))
+	data, err := ioutil.ReadFile(fw.segmentFilename(date, 1))
+	assert.Nil(t, err)
+	assert.Equal(t, "second line\n", string(data))
+	assert.Equal(t, 1, fw.lastFileSeq(date))
+
+	assert.Panics(t, func() {
+		NewFileWriter(filename, Daily, SetFilenamePattern("{name}"))
+	})
+}
+
+func TestOpenFileWriterDegraded(t *testing.T) {
+	dir := t.TempDir()
+	blocker := filepath.Join(dir, "logs")
+	assert.Nil(t, ioutil.WriteFile(blocker, nil, 0644))
+	filename := filepath.Join(blocker, "app.log")
+
+	w, err := OpenFileWriter(filename, Daily)
+	assert.NotNil(t, err)
+	assert.Nil(t, w)
+	assert.Panics(t, func() {
+		NewFileWriter(filename, Daily)
+	})
+
+	fallback := new(bytes.Buffer)
+	clock := &fakeClock{now: time.Date(2022, 3, 4, 5, 0, 0, 0, time.UTC)}
+	w, err = OpenFileWriter(filename, Daily, SetDegradedMode(time.Minute), SetClock(clock), SetLocation(time.UTC),
+		func(w *FileWriter) { w.fallback = fallback })
+	assert.Nil(t, err)
+	assert.Nil(t, w.Write([]byte("one")))
+
+	assert.Nil(t, os.Remove(blocker))
+	clock.Set(clock.Now().Add(30 * time.Second))
+	assert.Nil(t, w.Write([]byte("two")))
+	clock.Set(clock.Now().Add(time.Minute))
+	assert.Nil(t, w.Write([]byte("three")))
+	assert.Nil(t, w.Close())
+
+	assert.Equal(t, "one\ntwo\n", fallback.String())
+	data, err := ioutil.ReadFile(filepath.Join(blocker, "app.log.2022-03-04_00"))
+	assert.Nil(t, err)
+	assert.Equal(t, "three\n", string(data))
+}
+
+func countOpenFiles(t *testing.T) int {
+	entries, err := os.ReadDir("/proc/self/fd")
+	if err != nil {
+		t.Skip(err)
+	}
+	return len(entries)
+}
+
+func TestFileWriterClosesRotatedFiles(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "app.log")
+
+	before := countOpenFiles(t)
+	clock := &fakeClock{now: time.Date(2022, 3, 4, 5, 0, 0, 0, time.UTC)}
+	w := NewFileWriter(filename, Minutely, SetClock(clock), SetLocation(time.UTC), SetMaxFileSize(10))
+	for i := 0; i < 200; i++ {
+		if i%2 == 0 {
+			clock.Set(clock.Now().Add(time.Minute))
+		}
+		assert.Nil(t, w.Write([]byte("a line longer than the max size")))
+	}
+	assert.LessOrEqual(t, countOpenFiles(t), before+1)
+	assert.Nil(t, w.Close())
+	assert.Nil(t, w.Close())
+	assert.LessOrEqual(t, countOpenFiles(t), before)
+}
+
+func TestFileWriterReopen(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "app.log")
+
+	w, err := OpenFileWriter(filename, Daily, SetReopenSignals(syscall.SIGHUP))
+	assert.Nil(t, err)
+	current := w.segmentFilename(w.currentTimeSeg, 0)
+	read := func(name string) string {
+		_ = w.Flush()
+		data, _ := ioutil.ReadFile(name)
+		return string(data)
+	}
+
+	assert.Nil(t, w.Write([]byte("one")))
+	_ = w.Flush()
+	assert.Nil(t, os.Rename(current, current+".moved"))
+	assert.Nil(t, w.Reopen())
+	assert.Nil(t, w.Write([]byte("two")))
+	assert.Equal(t, "one\n", read(current+".moved"))
+	assert.Equal(t, "two\n", read(current))
+
+	// logrotate moves the file and sends SIGHUP
+	proc, err := os.FindProcess(os.Getpid())
+	assert.Nil(t, err)
+	assert.Nil(t, os.Rename(current, current+".rotated"))
+	if err := proc.Signal(syscall.SIGHUP); err != nil {
+		t.Skip(err)
+	}
+	assert.Eventually(t, func() bool {
+		_, err := os.Stat(current)
+		return err == nil
+	}, time.Second, time.Millisecond)
+	assert.Nil(t, w.Write([]byte("three")))
+	assert.Nil(t, w.Close())
+	assert.Equal(t, "two\n", read(current+".rotated"))
+	assert.Equal(t, "three\n", read(current))
+}
+
+func TestFileWriterReopenCheck(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "app.log")
+
+	w, err := OpenFileWriter(filename, Daily, SetReopenCheck(10*time.Millisecond))
+	assert.Nil(t, err)
+	current := w.segmentFilename(w.currentTimeSeg, 0)
+	assert.Nil(t, w.Write([]byte("one")))
+	assert.Nil(t, os.Remove(current))
+	assert.Eventually(t, func() bool {
+		_, err := os.Stat(current)
+		return err == nil
+	}, time.Second, 10*time.Millisecond)
+	assert.Nil(t, w.Write([]byte("two")))
+	assert.Nil(t, w.Close())
+
+	data, err := ioutil.ReadFile(current)
+	assert.Nil(t, err)
+	assert.Equal(t, "two\n", string(data))
+}
+
+type syncCountingFile struct {
+	sync.Mutex
+	bytes.Buffer
+	syncs int
+}
+
+func (f *syncCountingFile) Sync() error {
+	f.Lock()
+	f.syncs++
+	f.Unlock()
+	return nil
+}
+
+func (f *syncCountingFile) Syncs() int {
+	f.Lock()
+	defer f.Unlock()
+	return f.syncs
+}
+
+func (f *syncCountingFile) Close() error {
+	return nil
+}
+
+func TestFileWriterSyncPolicy(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "app.log")
+
+	w, err := OpenFileWriter(filename, Daily, SetSyncPolicy(SyncOnError))
+	assert.Nil(t, err)
+	file := &syncCountingFile{}
+	w.file.Rotate(file)
+	assert.Nil(t, w.WriteLevel(INFO, []byte("info")))
+	assert.Nil(t, w.Write([]byte("unknown")))
+	assert.Equal(t, 0, file.Syncs())
+	assert.Nil(t, w.WriteLevel(ERROR, []byte("error")))
+	assert.Equal(t, 1, file.Syncs())
+	assert.Equal(t, "info\nunknown\nerror\n", file.String())
+	assert.Nil(t, w.Close())
+	assert.Equal(t, 2, file.Syncs())
+
+	w, err = OpenFileWriter(filename, Daily, SetSyncPolicy(SyncAlways))
+	assert.Nil(t, err)
+	file = &syncCountingFile{}
+	w.file.Rotate(file)
+	assert.Nil(t, w.Write([]byte("one")))
+	assert.Nil(t, w.Write([]byte("two")))
+	assert.Equal(t, 2, file.Syncs())
+	assert.Nil(t, w.Close())
+
+	w, err = OpenFileWriter(filename, Daily, SetSyncPolicy(SyncPeriodically), SetSyncInterval(10*time.Millisecond))
+	assert.Nil(t, err)
+	file = &syncCountingFile{}
+	w.file.Rotate(file)
+	assert.Eventually(t, func() bool {
+		return file.Syncs() > 0
+	}, time.Second, 10*time.Millisecond)
+	assert.Nil(t, w.Close())
+}
+
+func TestFileWriterBufferSize(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "app.log")
+
+	w, err := OpenFileWriter(filename, Daily, SetBufferSize(0), SetFlushInterval(time.Hour))
+	assert.Nil(t, err)
+	assert.Nil(t, w.Write([]byte("written at once")))
+	data, err := ioutil.ReadFile(w.currentName)
+	assert.Nil(t, err)
+	assert.Equal(t, "written at once\n", string(data))
+	assert.Nil(t, w.Close())
+}
+
+func TestFileWriterIgnoresForeignFiles(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "app.log")
+	var foreign []string
+	for _, name := range []string{"app.log.1", "app.log.2.gz", "app.log.3"} {
+		path := filepath.Join(dir, name)
+		assert.Nil(t, ioutil.WriteFile(path, []byte("logrotate"), 0644))
+		foreign = append(foreign, path)
+	}
+	rotated := filepath.Join(dir, "app.log."+time.Now().Add(-time.Hour).Format(dateFormat))
+	assert.Nil(t, ioutil.WriteFile(rotated, []byte("rotated"), 0644))
+
+	w := NewFileWriter(filename, Hourly, SetLimitFiles(1), SetCompress(gzip.BestSpeed))
+	assert.Eventually(t, func() bool {
+		_, err := os.Stat(rotated)
+		return os.IsNotExist(err)
+	}, time.Second, 10*time.Millisecond)
+	assert.Nil(t, w.Close())
+	for _, path := range foreign {
+		data, err := ioutil.ReadFile(path)
+		assert.Nil(t, err, path)
+		assert.Equal(t, "logrotate", string(data), path)
+	}
+}
+
+func TestOpenFileWriterInvalidOptions(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "app.log")
+	for _, d := range []time.Duration{-time.Hour, 30 * time.Second, 90 * time.Second, 7 * time.Minute, 25 * time.Hour, 48 * time.Hour} {
+		_, err := OpenFileWriter(filename, Daily, SetRotationInterval(d))
+		assert.NotNil(t, err, d.String())
+	}
+	for _, d := range []time.Duration{15 * time.Minute, 6 * time.Hour, 24 * time.Hour} {
+		w, err := OpenFileWriter(filename, Daily, SetRotationInterval(d))
+		assert.Nil(t, err, d.String())
+		assert.Nil(t, w.Close())
+	}
+	_, err := OpenFileWriter(filename, Daily, SetTimeLayout("2006/01/02"))
+	assert.NotNil(t, err)
+	_, err = NewWriter("file", Options{"filename": filename, "rotation": "7m"})
+	assert.NotNil(t, err)
+}
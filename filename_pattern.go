@@ -0,0 +1,154 @@
+/*
+ * Copyright 2022 ByteDance and/or its affiliates.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dyclog
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultFilenamePattern names rotated files like dyc.log.2006-01-02_15 and
+// dyc.log.2006-01-02_15.1 for the following files of the same window.
+const DefaultFilenamePattern = "{name}.{date}.{seq}"
+
+var patternToken = regexp.MustCompile(`\{date\}|\{seq\}`)
+
+// filenamePattern formats and parses the names of rotated files from a
+// template where {name} is the base name of the log file, e.g. dyc.log,
+// {base} the name without extension, {ext} the extension, {date} the start
+// of the time window and {seq} the sequence number within the window.
+// {seq} and the '.', '-' or '_' before it are omitted for the first file.
+type filenamePattern struct {
+	layout   string
+	location *time.Location
+	// literals[i] precedes tokens[i], the last literal follows all tokens.
+	literals []string
+	tokens   []string
+	re       *regexp.Regexp
+	dateIdx  int
+	seqIdx   int
+}
+
+func newFilenamePattern(template, filename, layout string, location *time.Location) (*filenamePattern, error) {
+	if strings.ContainsRune(template, filepath.Separator) || strings.ContainsRune(template, '/') {
+		return nil, fmt.Errorf("filename pattern %q must not contain a path separator", template)
+	}
+	if strings.ContainsRune(layout, filepath.Separator) || strings.ContainsRune(layout, '/') {
+		return nil, fmt.Errorf("time layout %q must not contain a path separator", layout)
+	}
+	if strings.Count(template, "{date}") != 1 || strings.Count(template, "{seq}") != 1 {
+		return nil, fmt.Errorf("filename pattern %q must contain {date} and {seq} once", template)
+	}
+	name := filepath.Base(filename)
+	ext := filepath.Ext(name)
+	template = strings.NewReplacer(
+		"{name}", name,
+		"{base}", strings.TrimSuffix(name, ext),
+		"{ext}", ext,
+	).Replace(template)
+
+	p := &filenamePattern{layout: layout, location: location}
+	last := 0
+	for _, loc := range patternToken.FindAllStringIndex(template, -1) {
+		p.literals = append(p.literals, template[last:loc[0]])
+		p.tokens = append(p.tokens, template[loc[0]:loc[1]])
+		last = loc[1]
+	}
+	p.literals = append(p.literals, template[last:])
+
+	expr := "^"
+	for i, token := range p.tokens {
+		literal := p.literals[i]
+		switch token {
+		case "{date}":
+			expr += regexp.QuoteMeta(literal) + "(.+?)"
+			p.dateIdx = i + 1
+		case "{seq}":
+			if sep := seqSeparator(literal); sep != "" {
+				expr += regexp.QuoteMeta(literal[:len(literal)-1]) + "(?:" + regexp.QuoteMeta(sep) + "(\\d+))?"
+			} else {
+				expr += regexp.QuoteMeta(literal) + "(\\d+)?"
+			}
+			p.seqIdx = i + 1
+		}
+	}
+	expr += regexp.QuoteMeta(p.literals[len(p.literals)-1]) + "(?:" + regexp.QuoteMeta(compressSuffix) + ")?$"
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, err
+	}
+	p.re = re
+	return p, nil
+}
+
+// seqSeparator returns the separator ending literal, which is omitted with {seq}.
+func seqSeparator(literal string) string {
+	if literal == "" || !strings.ContainsRune(".-_", rune(literal[len(literal)-1])) {
+		return ""
+	}
+	return literal[len(literal)-1:]
+}
+
+// format returns the base name of the seq-th file of the window starting at date.
+func (p *filenamePattern) format(date time.Time, seq int) string {
+	date = date.In(p.location)
+	var b strings.Builder
+	for i, token := range p.tokens {
+		literal := p.literals[i]
+		switch token {
+		case "{date}":
+			b.WriteString(literal)
+			b.WriteString(date.Format(p.layout))
+		case "{seq}":
+			if seq == 0 {
+				b.WriteString(literal[:len(literal)-len(seqSeparator(literal))])
+				continue
+			}
+			b.WriteString(literal)
+			b.WriteString(strconv.Itoa(seq))
+		}
+	}
+	b.WriteString(p.literals[len(p.literals)-1])
+	return b.String()
+}
+
+// parse parses a base name returned by format, optionally compressed, ok is
+// false if name does not follow the pattern.
+func (p *filenamePattern) parse(name string) (date time.Time, seq int, ok bool) {
+	m := p.re.FindStringSubmatch(name)
+	if m == nil {
+		return date, 0, false
+	}
+	if m[p.seqIdx] != "" {
+		n, err := strconv.Atoi(m[p.seqIdx])
+		if err != nil || n <= 0 {
+			return date, 0, false
+		}
+		seq = n
+	}
+	// the date must read back as formatted, so files like name.1 left by
+	// other tools are never taken for rotated ones.
+	date, err := time.ParseInLocation(p.layout, m[p.dateIdx], p.location)
+	if err != nil || date.Format(p.layout) != m[p.dateIdx] {
+		return date, 0, false
+	}
+	return date, seq, true
+}
@@ -0,0 +1,73 @@
+/*
+ * Copyright 2022 ByteDance and/or its affiliates.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dyclog
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFilenamePatternDefault(t *testing.T) {
+	p, err := newFilenamePattern(DefaultFilenamePattern, "./logs/app.log", dateFormat, time.Local)
+	assert.Nil(t, err)
+	date := time.Date(2022, 3, 4, 5, 0, 0, 0, time.Local)
+	assert.Equal(t, "app.log.2022-03-04_05", p.format(date, 0))
+	assert.Equal(t, "app.log.2022-03-04_05.12", p.format(date, 12))
+
+	for name, seq := range map[string]int{
+		"app.log.2022-03-04_05":      0,
+		"app.log.2022-03-04_05.12":   12,
+		"app.log.2022-03-04_05.gz":   0,
+		"app.log.2022-03-04_05.3.gz": 3,
+	} {
+		d, n, ok := p.parse(name)
+		assert.True(t, ok, name)
+		assert.Equal(t, seq, n, name)
+		assert.True(t, date.Equal(d), name)
+	}
+	for _, name := range []string{
+		"app.log", "app.log.bak", "app.log.2022-03-04_05.x", "app.log.2022-03-04_05.0", "app.log.1.2022-03-04_05",
+		"app.log.1", "app.log.2.gz", "app.log.2022-03-04_5",
+	} {
+		_, _, ok := p.parse(name)
+		assert.False(t, ok, name)
+	}
+}
+
+func TestFilenamePatternTemplate(t *testing.T) {
+	p, err := newFilenamePattern("{base}-{date}.{seq}{ext}", "app.log", "20060102", time.Local)
+	assert.Nil(t, err)
+	date := time.Date(2022, 3, 4, 0, 0, 0, 0, time.Local)
+	assert.Equal(t, "app-20220304.log", p.format(date, 0))
+	assert.Equal(t, "app-20220304.2.log", p.format(date, 2))
+
+	_, seq, ok := p.parse("app-20220304.2.log.gz")
+	assert.True(t, ok)
+	assert.Equal(t, 2, seq)
+	_, seq, ok = p.parse("app-20220304.log")
+	assert.True(t, ok)
+	assert.Equal(t, 0, seq)
+	_, _, ok = p.parse("app.log.20220304")
+	assert.False(t, ok)
+
+	_, err = newFilenamePattern("{name}.{date}", "app.log", dateFormat, time.Local)
+	assert.NotNil(t, err)
+	_, err = newFilenamePattern("old/{name}.{date}.{seq}", "app.log", dateFormat, time.Local)
+	assert.NotNil(t, err)
+}
@@ -28,8 +28,139 @@ const (
 	fieldKeyLogID          = "logid"
 	fieldKeyIP             = "ip"
 	fieldKeyLocation       = "location"
+	fieldKeyFunction       = "func"
 )
 
+// Formatter encodes an entry into b, it must not retain b or the entry.
 type Formatter interface {
-	Format(log) ([]byte, error)
+	Format(entry *Entry, b *Buffer) error
+}
+
+// fieldOptions are the options of the fixed fields shared by the built-in
+// formatters, they are embedded so the setters are promoted.
+type fieldOptions struct {
+	enableTimestamp bool
+	timestampFormat string
+	disableIP       bool
+	localIP         string
+	ipResolver      *IPResolver
+
+	enableFunction   bool
+	callerFormat     CallerFormat
+	callerTrimPrefix string
+}
+
+func newFieldOptions(enableTimestamp bool) fieldOptions {
+	return fieldOptions{
+		enableTimestamp: enableTimestamp,
+		timestampFormat: defaultTimestampFormat,
+		ipResolver:      defaultIPResolver,
+	}
+}
+
+// SetTimestampFormat sets the layout of the time field.
+func (o *fieldOptions) SetTimestampFormat(layout string) {
+	o.timestampFormat = layout
+}
+
+func (o *fieldOptions) SetTimestamp(enable bool) {
+	o.enableTimestamp = enable
+}
+
+// SetFunction enables or disables the caller function name field.
+func (o *fieldOptions) SetFunction(enable bool) {
+	o.enableFunction = enable
+}
+
+// SetCallerFormat sets how the file of the location field is written.
+func (o *fieldOptions) SetCallerFormat(format CallerFormat) {
+	o.callerFormat = format
+}
+
+// SetCallerTrimPrefix sets the prefix, such as the module root or GOPATH,
+// trimmed from the location field when using CallerFullPath.
+func (o *fieldOptions) SetCallerTrimPrefix(prefix string) {
+	o.callerTrimPrefix = prefix
+}
+
+// SetIP enables or disables the local IP field.
+func (o *fieldOptions) SetIP(enable bool) {
+	o.disableIP = !enable
+}
+
+// SetLocalIP overrides the local IP field with ip instead of resolving it.
+func (o *fieldOptions) SetLocalIP(ip string) {
+	o.localIP = ip
+}
+
+// SetIPInterface resolves the local IP field from the named interface.
+func (o *fieldOptions) SetIPInterface(name string) {
+	o.ipResolver = NewIPResolver(name, defaultIPRefreshInterval)
+}
+
+// SetIPResolver sets the IPResolver used to resolve the local IP field.
+func (o *fieldOptions) SetIPResolver(resolver *IPResolver) {
+	o.ipResolver = resolver
+}
+
+func (o *fieldOptions) getLocalIP() string {
+	if o.localIP != "" {
+		return o.localIP
+	}
+	if o.ipResolver == nil {
+		return defaultIPResolver.IP()
+	}
+	return o.ipResolver.IP()
+}
+
+// appendFixedKeys appends the enabled fixed keys in their output order.
+func (o *fieldOptions) appendFixedKeys(keys []string) []string {
+	if o.enableTimestamp {
+		keys = append(keys, fieldKeyTime)
+	}
+	keys = append(keys, fieldKeyLevel)
+	keys = append(keys, fieldKeyLogID)
+	keys = append(keys, fieldKeyLocation)
+	if o.enableFunction {
+		keys = append(keys, fieldKeyFunction)
+	}
+	if !o.disableIP {
+		keys = append(keys, fieldKeyIP)
+	}
+	return append(keys, fieldKeyMessage)
+}
+
+// fieldKeyPrefix is prepended to the keys of fields named like a fixed key,
+// so they do not overwrite it.
+const fieldKeyPrefix = "fields."
+
+func isFixedKey(key string, fixedKeys []string) bool {
+	for _, fixed := range fixedKeys {
+		if key == fixed {
+			return true
+		}
+	}
+	return false
+}
+
+// appendFixedValue writes the plain text of the fixed field key of l.
+func (o *fieldOptions) appendFixedValue(b *Buffer, l *Entry, key string) {
+	switch key {
+	case fieldKeyTime:
+		b.AppendTime(l.Time, o.timestampFormat)
+	case fieldKeyIP:
+		_, _ = b.WriteString(o.getLocalIP())
+	case fieldKeyLogID:
+		_, _ = b.WriteString(GetLogIDFromCtx(l.Context))
+	case fieldKeyLevel:
+		_, _ = b.WriteString(l.Level.String())
+	case fieldKeyMessage:
+		_, _ = b.WriteString(l.Message)
+	case fieldKeyLocation:
+		_, _ = b.WriteString(GetCallerFile(l.Caller, o.callerFormat, o.callerTrimPrefix))
+		_ = b.WriteByte(':')
+		b.AppendInt(int64(l.Caller.Line))
+	case fieldKeyFunction:
+		_, _ = b.WriteString(GetCallerFunction(l.Caller))
+	}
 }
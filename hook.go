@@ -0,0 +1,125 @@
+/*
+ * Copyright 2022 ByteDance and/or its affiliates.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dyclog
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// Hook is fired with every entry whose level is one of Levels, after level
+// filtering and processors and before the entry is formatted. The entry must
+// not be retained after Fire returns.
+type Hook interface {
+	Levels() []Level
+	Fire(entry *Entry) error
+}
+
+type funcHook struct {
+	levels []Level
+	fire   func(entry *Entry) error
+}
+
+// NewFuncHook creates a Hook calling fire on the given levels.
+func NewFuncHook(fire func(entry *Entry) error, levels ...Level) Hook {
+	return &funcHook{
+		levels: levels,
+		fire:   fire,
+	}
+}
+
+func (h *funcHook) Levels() []Level {
+	return h.levels
+}
+
+func (h *funcHook) Fire(entry *Entry) error {
+	return h.fire(entry)
+}
+
+// AsyncHook fires the wrapped Hook in a background goroutine so slow hooks,
+// like sending alerts over network, do not block logging.
+type AsyncHook struct {
+	Hook
+	done   *sync.WaitGroup
+	ch     chan *Entry
+	omit   bool
+	mu     sync.RWMutex
+	closed bool
+}
+
+// NewAsyncHook wraps hook to be fired asynchronously, when omit is true
+// entries are discarded instead of blocking if the queue is full.
+func NewAsyncHook(hook Hook, omit bool) *AsyncHook {
+	asyncHook := &AsyncHook{
+		Hook: hook,
+		done: &sync.WaitGroup{},
+		ch:   make(chan *Entry, 1024),
+		omit: omit,
+	}
+	go asyncHook.runWorker()
+	return asyncHook
+}
+
+func (h *AsyncHook) runWorker() {
+	for entry := range h.ch {
+		if err := h.Hook.Fire(entry); err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "log async hook fires error: %s\n", err)
+		}
+		h.done.Done()
+	}
+}
+
+// Fire queues a copy of entry to be fired by the background goroutine,
+// the entry is silently dropped once the hook is closed.
+func (h *AsyncHook) Fire(entry *Entry) error {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	if h.closed {
+		return nil
+	}
+	h.done.Add(1)
+	if h.omit {
+		select {
+		case h.ch <- entry.clone():
+		default:
+			h.done.Done()
+		}
+	} else {
+		h.ch <- entry.clone()
+	}
+	return nil
+}
+
+// Close waits for queued entries to be fired and stops the goroutine,
+// closing it again does nothing.
+func (h *AsyncHook) Close() error {
+	h.mu.Lock()
+	if h.closed {
+		h.mu.Unlock()
+		return nil
+	}
+	h.closed = true
+	close(h.ch)
+	h.mu.Unlock()
+	h.done.Wait()
+	if c, ok := h.Hook.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
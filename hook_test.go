@@ -0,0 +1,89 @@
+/*
+ * Copyright 2022 ByteDance and/or its affiliates.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dyclog
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHookLevels(t *testing.T) {
+	logger := NewLogger(new(BufferWriter))
+	logger.SetLevel(INFO)
+	var count int32
+	var message string
+	logger.AddHook(NewFuncHook(func(entry *Entry) error {
+		atomic.AddInt32(&count, 1)
+		message = entry.Message
+		return nil
+	}, DEBUG, ERROR, FATAL))
+
+	logger.Debug("filtered by level")
+	logger.Warn("not hooked")
+	logger.Error("db %s", "down")
+	assert.Equal(t, int32(1), atomic.LoadInt32(&count))
+	assert.Equal(t, "db down", message)
+
+	logger.AddHook(NewFuncHook(func(entry *Entry) error {
+		return errors.New("webhook unavailable")
+	}, FATAL))
+	logger.Fatal("still written")
+	assert.Equal(t, int32(2), atomic.LoadInt32(&count))
+	assert.Contains(t, logger.GetWriter().(*BufferWriter).String(), "still written")
+}
+
+func TestAsyncHook(t *testing.T) {
+	logger := NewLogger(new(BufferWriter))
+	messages := make([]string, 0)
+	logger.AddHook(NewAsyncHook(NewFuncHook(func(entry *Entry) error {
+		messages = append(messages, entry.Message+" "+entry.Fields[0].Key)
+		return nil
+	}, ERROR), false))
+	logger.AddProcessor(func(entry *Entry) bool {
+		entry.AddField("alert", true)
+		return true
+	})
+
+	logger.Error("first")
+	logger.Error("second")
+	assert.Nil(t, logger.Close())
+	assert.Equal(t, []string{"first alert", "second alert"}, messages)
+}
+
+func TestAsyncHookClose(t *testing.T) {
+	var fired int32
+	hook := NewAsyncHook(NewFuncHook(func(entry *Entry) error {
+		atomic.AddInt32(&fired, 1)
+		return nil
+	}, ERROR), false)
+	first := NewLogger(new(BufferWriter))
+	second := NewLogger(new(BufferWriter))
+	first.AddHook(hook)
+	second.AddHook(hook)
+
+	second.Error("fired")
+	assert.Nil(t, first.Close())
+	assert.Equal(t, int32(1), atomic.LoadInt32(&fired))
+	assert.Nil(t, hook.Fire(&Entry{Level: ERROR}))
+	second.Error("dropped by the hook")
+	assert.Nil(t, second.Close())
+	assert.Equal(t, int32(1), atomic.LoadInt32(&fired))
+	assert.Contains(t, second.GetWriter().(*BufferWriter).String(), "dropped by the hook")
+}
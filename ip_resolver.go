@@ -0,0 +1,72 @@
+/*
+ * Copyright 2022 ByteDance and/or its affiliates.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dyclog
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const defaultIPRefreshInterval = time.Minute
+
+var defaultIPResolver = NewIPResolver("", defaultIPRefreshInterval)
+
+// IPResolver caches the local IP written into log lines, it is resolved on
+// first use and again in the background once the refresh interval has passed.
+type IPResolver struct {
+	expire  int64
+	refresh time.Duration
+	lookup  func() string
+	once    sync.Once
+	ip      atomic.Value
+}
+
+// NewIPResolver creates an IPResolver for the named interface, or for all
+// interfaces if iface is empty. A non-positive refresh never re-resolves.
+func NewIPResolver(iface string, refresh time.Duration) *IPResolver {
+	lookup := GetLocalIP
+	if iface != "" {
+		lookup = func() string {
+			return GetInterfaceIP(iface)
+		}
+	}
+	return &IPResolver{
+		refresh: refresh,
+		lookup:  lookup,
+	}
+}
+
+// IP returns the cached local IP. When the cache expires, one caller starts
+// resolving it in a goroutine and every caller keeps using the stale value
+// until it is done.
+func (r *IPResolver) IP() string {
+	r.once.Do(func() {
+		r.ip.Store(r.lookup())
+		atomic.StoreInt64(&r.expire, time.Now().UnixNano()+int64(r.refresh))
+	})
+	if r.refresh > 0 {
+		now := time.Now().UnixNano()
+		expire := atomic.LoadInt64(&r.expire)
+		if now >= expire && atomic.CompareAndSwapInt64(&r.expire, expire, now+int64(r.refresh)) {
+			go func() {
+				r.ip.Store(r.lookup())
+			}()
+		}
+	}
+	return r.ip.Load().(string)
+}
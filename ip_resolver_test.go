@@ -0,0 +1,69 @@
+/*
+ * Copyright 2022 ByteDance and/or its affiliates.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dyclog
+
+import (
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIPResolver(t *testing.T) {
+	r := NewIPResolver("", time.Millisecond)
+	assert.Equal(t, GetLocalIP(), r.IP())
+	time.Sleep(2 * time.Millisecond)
+	assert.Equal(t, GetLocalIP(), r.IP())
+
+	r = NewIPResolver("no-such-interface", 0)
+	assert.Equal(t, "", r.IP())
+}
+
+func TestIPResolverRefresh(t *testing.T) {
+	var lookups int32
+	release := make(chan struct{})
+	r := NewIPResolver("", time.Millisecond)
+	r.lookup = func() string {
+		if atomic.AddInt32(&lookups, 1) == 1 {
+			return "198.51.100.1"
+		}
+		<-release
+		return "198.51.100.2"
+	}
+	assert.Equal(t, int32(0), atomic.LoadInt32(&lookups))
+	assert.Equal(t, "198.51.100.1", r.IP())
+
+	time.Sleep(2 * time.Millisecond)
+	assert.Equal(t, "198.51.100.1", r.IP())
+	close(release)
+	assert.Eventually(t, func() bool {
+		return r.IP() == "198.51.100.2"
+	}, time.Second, time.Millisecond)
+}
+
+func TestPickIP(t *testing.T) {
+	loopback := &net.IPNet{IP: net.ParseIP("::1")}
+	linkLocal := &net.IPNet{IP: net.ParseIP("fe80::1")}
+	global := &net.IPNet{IP: net.ParseIP("2001:db8::1")}
+	v4 := &net.IPNet{IP: net.ParseIP("192.0.2.1")}
+
+	assert.Equal(t, "2001:db8::1", pickIP([]net.Addr{loopback, linkLocal, global}))
+	assert.Equal(t, "192.0.2.1", pickIP([]net.Addr{global, v4}))
+	assert.Equal(t, "", pickIP([]net.Addr{loopback}))
+}
@@ -0,0 +1,183 @@
+/*
+ * Copyright 2022 ByteDance and/or its affiliates.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dyclog
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"unicode/utf8"
+)
+
+const hex = "0123456789abcdef"
+
+// JSONFormatter writes every entry as a JSON object on a single line, fields
+// are written after the fixed keys with their JSON types kept. Fields named
+// like a fixed key are prefixed with "fields.".
+type JSONFormatter struct {
+	fieldOptions
+}
+
+// NewJSONFormatter creates a JSONFormatter with the time field enabled.
+func NewJSONFormatter() *JSONFormatter {
+	return &JSONFormatter{
+		fieldOptions: newFieldOptions(true),
+	}
+}
+
+func (f *JSONFormatter) Format(l *Entry, b *Buffer) error {
+	scratch := NewBuffer()
+	defer scratch.Free()
+
+	_ = b.WriteByte('{')
+	keys := f.appendFixedKeys(make([]string, 0, 7))
+	for _, key := range keys {
+		if l.Caller == nil && (key == fieldKeyLocation || key == fieldKeyFunction) {
+			continue
+		}
+		appendJSONKey(b, scratch, key)
+		scratch.Reset()
+		f.appendFixedValue(scratch, l, key)
+		appendJSONString(b, scratch.Bytes())
+	}
+	for _, field := range l.Fields {
+		if isFixedKey(field.Key, keys) {
+			_ = b.WriteByte(',')
+			scratch.Reset()
+			_, _ = scratch.WriteString(fieldKeyPrefix)
+			_, _ = scratch.WriteString(field.Key)
+			appendJSONString(b, scratch.Bytes())
+			_ = b.WriteByte(':')
+		} else {
+			appendJSONKey(b, scratch, field.Key)
+		}
+		appendJSONValue(b, scratch, field.Value)
+	}
+	_, _ = b.WriteString("}\n")
+	return nil
+}
+
+func appendJSONKey(b *Buffer, scratch *Buffer, key string) {
+	if b.Len() > 0 && b.b[b.Len()-1] != '{' {
+		_ = b.WriteByte(',')
+	}
+	appendJSONText(b, scratch, key)
+	_ = b.WriteByte(':')
+}
+
+// appendJSONValue writes value keeping its JSON type, scratch is used to
+// escape strings without allocating.
+func appendJSONValue(b *Buffer, scratch *Buffer, value interface{}) {
+	switch v := resolveLogValue(value).(type) {
+	case nil:
+		_, _ = b.WriteString("null")
+	case string:
+		appendJSONText(b, scratch, v)
+	case int:
+		b.AppendInt(int64(v))
+	case int64:
+		b.AppendInt(v)
+	case int32:
+		b.AppendInt(int64(v))
+	case uint:
+		b.b = strconv.AppendUint(b.b, uint64(v), 10)
+	case uint64:
+		b.b = strconv.AppendUint(b.b, v, 10)
+	case uint32:
+		b.b = strconv.AppendUint(b.b, uint64(v), 10)
+	case bool:
+		b.b = strconv.AppendBool(b.b, v)
+	case float64:
+		appendJSONFloat(b, v, 64)
+	case float32:
+		appendJSONFloat(b, float64(v), 32)
+	case json.Marshaler:
+		appendJSONMarshal(b, scratch, v)
+	case error:
+		appendJSONText(b, scratch, v.Error())
+	case fmt.Stringer:
+		appendJSONText(b, scratch, v.String())
+	default:
+		appendJSONMarshal(b, scratch, v)
+	}
+}
+
+func appendJSONFloat(b *Buffer, v float64, bitSize int) {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		_ = b.WriteByte('"')
+		b.b = strconv.AppendFloat(b.b, v, 'g', -1, bitSize)
+		_ = b.WriteByte('"')
+		return
+	}
+	b.b = strconv.AppendFloat(b.b, v, 'g', -1, bitSize)
+}
+
+func appendJSONMarshal(b *Buffer, scratch *Buffer, v interface{}) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		appendJSONText(b, scratch, fmt.Sprint(v))
+		return
+	}
+	_, _ = b.Write(data)
+}
+
+func appendJSONText(b *Buffer, scratch *Buffer, s string) {
+	scratch.Reset()
+	_, _ = scratch.WriteString(s)
+	appendJSONString(b, scratch.Bytes())
+}
+
+// appendJSONString writes s as a quoted JSON string, invalid UTF-8 is
+// replaced by U+FFFD.
+func appendJSONString(b *Buffer, s []byte) {
+	_ = b.WriteByte('"')
+	for i := 0; i < len(s); {
+		c := s[i]
+		if c >= utf8.RuneSelf {
+			r, size := utf8.DecodeRune(s[i:])
+			if r == utf8.RuneError && size == 1 {
+				_, _ = b.WriteString("\ufffd")
+			} else {
+				_, _ = b.Write(s[i : i+size])
+			}
+			i += size
+			continue
+		}
+		switch c {
+		case '"', '\\':
+			_ = b.WriteByte('\\')
+			_ = b.WriteByte(c)
+		case '\n':
+			_, _ = b.WriteString(`\n`)
+		case '\r':
+			_, _ = b.WriteString(`\r`)
+		case '\t':
+			_, _ = b.WriteString(`\t`)
+		default:
+			if c < 0x20 {
+				_, _ = b.WriteString(`\u00`)
+				_ = b.WriteByte(hex[c>>4])
+				_ = b.WriteByte(hex[c&0xf])
+			} else {
+				_ = b.WriteByte(c)
+			}
+		}
+		i++
+	}
+	_ = b.WriteByte('"')
+}
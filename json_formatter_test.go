@@ -0,0 +1,86 @@
+/*
+ * Copyright 2022 ByteDance and/or its affiliates.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dyclog
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJSONFormatter(t *testing.T) {
+	now := time.Date(2026, 10, 18, 15, 4, 5, 0, time.UTC)
+	l := &Entry{
+		Level:   WARN,
+		Time:    now,
+		Message: "say \"hi\"\n\x01",
+		Context: InjectLogIDToCtx(context.Background(), "1234567890"),
+		Caller:  GetCaller(1),
+	}
+	l.AddField("count", 3)
+	l.AddField("ok", true)
+	l.AddField("ratio", 0.5)
+	l.AddField("nan", math.NaN())
+	l.AddField("err", errors.New("boom"))
+	l.AddField("tags", []string{"a", "b"})
+	l.AddField("nil", nil)
+
+	f := NewJSONFormatter()
+	f.SetLocalIP("10.0.0.1")
+	f.SetFunction(true)
+	b, err := format(f, l)
+	assert.Nil(t, err)
+	assert.Equal(t, `{"time":"2026-10-18T15:04:05Z","level":"WARN","logid":"1234567890",`+
+		`"location":"json_formatter_test.go:37","func":"go-dyclog.TestJSONFormatter","ip":"10.0.0.1",`+
+		`"message":"say \"hi\"\n\u0001","count":3,"ok":true,"ratio":0.5,"nan":"NaN","err":"boom",`+
+		`"tags":["a","b"],"nil":null}`+"\n", string(b))
+
+	var decoded map[string]interface{}
+	assert.Nil(t, json.Unmarshal(b, &decoded))
+	assert.Equal(t, "say \"hi\"\n\x01", decoded["message"])
+
+	l.Caller = nil
+	l.Fields = nil
+	l.Message = "invalid \xff utf8"
+	f.SetTimestamp(false)
+	f.SetIP(false)
+	b, _ = format(f, l)
+	assert.Equal(t, `{"level":"WARN","logid":"1234567890","message":"invalid `+"�"+` utf8"}`+"\n", string(b))
+}
+
+func TestJSONFormatterFixedKeyFields(t *testing.T) {
+	l := &Entry{Level: INFO, Message: "x", Context: context.Background()}
+	l.AddField("message", "dup")
+	l.AddField("level", "dup")
+
+	f := NewJSONFormatter()
+	f.SetTimestamp(false)
+	f.SetIP(false)
+	b, err := format(f, l)
+	assert.Nil(t, err)
+	assert.Equal(t, `{"level":"INFO","logid":"-","message":"x","fields.message":"dup","fields.level":"dup"}`+"\n", string(b))
+
+	var decoded map[string]interface{}
+	assert.Nil(t, json.Unmarshal(b, &decoded))
+	assert.Equal(t, "x", decoded["message"])
+	assert.Equal(t, "dup", decoded["fields.message"])
+}
@@ -18,6 +18,11 @@ package dyclog
 
 import (
 	"errors"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 )
 
 var ErrInvalidLogLevel = errors.New("logger: invalid log level")
@@ -25,7 +30,7 @@ var ErrInvalidLogLevel = errors.New("logger: invalid log level")
 type Level int
 
 const (
-	InvalidLevel Level = iota - 1
+	TRACE Level = iota - 1
 	DEBUG
 	INFO
 	NOTICE
@@ -34,7 +39,11 @@ const (
 	FATAL
 )
 
+// InvalidLevel is returned by ParseLevel for unknown levels.
+const InvalidLevel Level = math.MinInt32
+
 const (
+	sTrace  = "TRACE"
 	sDebug  = "DEBUG"
 	sInfo   = "INFO"
 	sNotice = "NOTICE"
@@ -45,6 +54,7 @@ const (
 
 var (
 	levelNames = []string{
+		sTrace,
 		sDebug,
 		sInfo,
 		sNotice,
@@ -54,6 +64,7 @@ var (
 	}
 
 	levelStrings = map[string]Level{
+		sTrace:  TRACE,
 		sDebug:  DEBUG,
 		sInfo:   INFO,
 		sNotice: NOTICE,
@@ -61,19 +72,157 @@ var (
 		sError:  ERROR,
 		sFatal:  FATAL,
 	}
+
+	levelColors = []int{
+		gray,
+		gray,
+		blue,
+		blue,
+		yellow,
+		red,
+		red,
+	}
 )
 
+// customLevel is a level registered by RegisterLevel.
+type customLevel struct {
+	name  string
+	color int
+}
+
+var (
+	// customLevels is initialized here rather than in init, since the
+	// default logger parses DYCLOG_LEVEL while initializing the package.
+	customLevels = func() (v atomic.Value) {
+		v.Store(map[Level]customLevel{})
+		return
+	}() // map[Level]customLevel
+	customLevelsMu sync.Mutex
+)
+
+func isBuiltinLevel(l Level) bool {
+	return l >= TRACE && l <= FATAL
+}
+
+func getCustomLevel(l Level) (customLevel, bool) {
+	c, ok := customLevels.Load().(map[Level]customLevel)[l]
+	return c, ok
+}
+
+// RegisterLevel registers a custom level with its name and the ANSI color
+// used by colored formatters. Levels are ordered by value, so a level above
+// FATAL is more severe than all built-in ones. It returns ErrInvalidLogLevel
+// if the level or the name is already taken.
+func RegisterLevel(level Level, name string, color int) error {
+	name = strings.ToUpper(name)
+	if level == InvalidLevel || name == "" || isBuiltinLevel(level) {
+		return ErrInvalidLogLevel
+	}
+	if _, ok := levelStrings[name]; ok {
+		return ErrInvalidLogLevel
+	}
+
+	customLevelsMu.Lock()
+	defer customLevelsMu.Unlock()
+	levels := customLevels.Load().(map[Level]customLevel)
+	if _, ok := levels[level]; ok {
+		return ErrInvalidLogLevel
+	}
+	updated := make(map[Level]customLevel, len(levels)+1)
+	for k, v := range levels {
+		if v.name == name {
+			return ErrInvalidLogLevel
+		}
+		updated[k] = v
+	}
+	updated[level] = customLevel{name: name, color: color}
+	customLevels.Store(updated)
+	return nil
+}
+
+// String returns the name of the level, or LEVEL(n) for unknown levels.
 func (l Level) String() string {
-	return levelNames[l]
+	if isBuiltinLevel(l) {
+		return levelNames[l-TRACE]
+	}
+	if c, ok := getCustomLevel(l); ok {
+		return c.name
+	}
+	return "LEVEL(" + strconv.Itoa(int(l)) + ")"
 }
 
+// Color returns the ANSI color of the level used by colored formatters.
+func (l Level) Color() int {
+	if isBuiltinLevel(l) {
+		return levelColors[l-TRACE]
+	}
+	if c, ok := getCustomLevel(l); ok {
+		return c.color
+	}
+	return blue
+}
+
+// ParseLevel parses a level name case-insensitively, numeric values of
+// built-in and registered levels are accepted too.
 func ParseLevel(s string) (Level, error) {
-	l, ok := levelStrings[s]
-	if !ok {
+	s = strings.ToUpper(strings.TrimSpace(s))
+	if l, ok := levelStrings[s]; ok {
+		return l, nil
+	}
+	if n, err := strconv.Atoi(s); err == nil {
+		l := Level(n)
+		if _, ok := getCustomLevel(l); ok || isBuiltinLevel(l) {
+			return l, nil
+		}
 		return InvalidLevel, ErrInvalidLogLevel
 	}
+	for l, c := range customLevels.Load().(map[Level]customLevel) {
+		if c.name == s {
+			return l, nil
+		}
+	}
+	return InvalidLevel, ErrInvalidLogLevel
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (l Level) MarshalText() ([]byte, error) {
+	if _, ok := getCustomLevel(l); !ok && !isBuiltinLevel(l) {
+		return nil, ErrInvalidLogLevel
+	}
+	return []byte(l.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (l *Level) UnmarshalText(text []byte) error {
+	parsed, err := ParseLevel(string(text))
+	if err != nil {
+		return err
+	}
+	*l = parsed
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler, the level is encoded as its name.
+func (l Level) MarshalJSON() ([]byte, error) {
+	text, err := l.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+	return []byte(strconv.Quote(string(text))), nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler, both names and numbers are accepted.
+func (l *Level) UnmarshalJSON(data []byte) error {
+	s := string(data)
+	if unquoted, err := strconv.Unquote(s); err == nil {
+		s = unquoted
+	}
+	return l.UnmarshalText([]byte(s))
+}
 
-	return l, nil
+// Set implements flag.Value.
+func (l *Level) Set(s string) error {
+	return l.UnmarshalText([]byte(s))
 }
 
 func MustParseLevel(s string) Level {
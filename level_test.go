@@ -17,6 +17,9 @@
 package dyclog
 
 import (
+	"context"
+	"encoding/json"
+	"flag"
 	"github.com/stretchr/testify/assert"
 	"testing"
 )
@@ -42,3 +45,73 @@ func TestParseLevel(t *testing.T) {
 		assert.Equal(t, l, v)
 	}
 }
+
+func TestLevelStringUnknown(t *testing.T) {
+	assert.Equal(t, sTrace, TRACE.String())
+	assert.Equal(t, "LEVEL(42)", Level(42).String())
+	assert.NotPanics(t, func() { _ = InvalidLevel.String() })
+}
+
+func TestParseLevelLenient(t *testing.T) {
+	data := map[string]Level{
+		"trace":   TRACE,
+		" Info ":  INFO,
+		"warn":    WARN,
+		"-1":      TRACE,
+		"4":       ERROR,
+		"unknown": InvalidLevel,
+		"99":      InvalidLevel,
+	}
+	for s, want := range data {
+		l, _ := ParseLevel(s)
+		assert.Equal(t, want, l, s)
+	}
+}
+
+func TestRegisterLevel(t *testing.T) {
+	levels := customLevels.Load()
+	t.Cleanup(func() {
+		customLevels.Store(levels)
+	})
+	audit := Level(10)
+	assert.Nil(t, RegisterLevel(audit, "audit", 35))
+	assert.Equal(t, ErrInvalidLogLevel, RegisterLevel(audit, "other", 35))
+	assert.Equal(t, ErrInvalidLogLevel, RegisterLevel(Level(11), "AUDIT", 35))
+	assert.Equal(t, ErrInvalidLogLevel, RegisterLevel(Level(12), "warn", 35))
+	assert.Equal(t, ErrInvalidLogLevel, RegisterLevel(WARN, "warning", 35))
+
+	assert.Equal(t, "AUDIT", audit.String())
+	assert.Equal(t, 35, audit.Color())
+	assert.Equal(t, audit, MustParseLevel("Audit"))
+	assert.Equal(t, audit, MustParseLevel("10"))
+
+	logger := NewLogger(new(BufferWriter))
+	logger.SetFormatter(NewTextFormatter(true))
+	logger.SetLevel(FATAL)
+	logger.Log(context.Background(), audit, "user deleted")
+	assert.Contains(t, logger.GetWriter().(*BufferWriter).String(), "\x1b[35m AUDIT - ")
+}
+
+func TestLevelMarshal(t *testing.T) {
+	type config struct {
+		Level Level `json:"level"`
+	}
+	b, err := json.Marshal(config{Level: WARN})
+	assert.Nil(t, err)
+	assert.Equal(t, `{"level":"WARN"}`, string(b))
+
+	var c config
+	assert.Nil(t, json.Unmarshal([]byte(`{"level":"error"}`), &c))
+	assert.Equal(t, ERROR, c.Level)
+	assert.Nil(t, json.Unmarshal([]byte(`{"level":1}`), &c))
+	assert.Equal(t, INFO, c.Level)
+	assert.NotNil(t, json.Unmarshal([]byte(`{"level":"verbose"}`), &c))
+	_, err = json.Marshal(config{Level: Level(77)})
+	assert.NotNil(t, err)
+
+	var l Level
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.Var(&l, "level", "log level")
+	assert.Nil(t, fs.Parse([]string{"-level", "notice"}))
+	assert.Equal(t, NOTICE, l)
+}
@@ -0,0 +1,101 @@
+/*
+ * Copyright 2022 ByteDance and/or its affiliates.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dyclog
+
+// LogfmtFormatter writes every entry as space separated key=value pairs,
+// values are quoted only when they contain spaces, quotes or '='. Fields
+// named like a fixed key are prefixed with "fields.".
+type LogfmtFormatter struct {
+	fieldOptions
+}
+
+// NewLogfmtFormatter creates a LogfmtFormatter with the time field enabled.
+func NewLogfmtFormatter() *LogfmtFormatter {
+	return &LogfmtFormatter{
+		fieldOptions: newFieldOptions(true),
+	}
+}
+
+func (f *LogfmtFormatter) Format(l *Entry, b *Buffer) error {
+	scratch := NewBuffer()
+	defer scratch.Free()
+
+	start := b.Len()
+	keys := f.appendFixedKeys(make([]string, 0, 7))
+	for _, key := range keys {
+		if l.Caller == nil && (key == fieldKeyLocation || key == fieldKeyFunction) {
+			continue
+		}
+		if b.Len() > start {
+			_ = b.WriteByte(' ')
+		}
+		_, _ = b.WriteString(key)
+		_ = b.WriteByte('=')
+		scratch.Reset()
+		f.appendFixedValue(scratch, l, key)
+		appendLogfmtValue(b, scratch.Bytes())
+	}
+	for _, field := range l.Fields {
+		_ = b.WriteByte(' ')
+		if isFixedKey(field.Key, keys) {
+			_, _ = b.WriteString(fieldKeyPrefix)
+		}
+		_, _ = b.WriteString(field.Key)
+		_ = b.WriteByte('=')
+		scratch.Reset()
+		appendValue(scratch, field.Value)
+		appendLogfmtValue(b, scratch.Bytes())
+	}
+	_ = b.WriteByte('\n')
+	return nil
+}
+
+func appendLogfmtValue(b *Buffer, s []byte) {
+	if !needsLogfmtQuote(s) {
+		_, _ = b.Write(s)
+		return
+	}
+	_ = b.WriteByte('"')
+	for _, c := range s {
+		switch c {
+		case '"', '\\':
+			_ = b.WriteByte('\\')
+			_ = b.WriteByte(c)
+		case '\n':
+			_, _ = b.WriteString(`\n`)
+		case '\r':
+			_, _ = b.WriteString(`\r`)
+		case '\t':
+			_, _ = b.WriteString(`\t`)
+		default:
+			_ = b.WriteByte(c)
+		}
+	}
+	_ = b.WriteByte('"')
+}
+
+func needsLogfmtQuote(s []byte) bool {
+	if len(s) == 0 {
+		return true
+	}
+	for _, c := range s {
+		if c <= ' ' || c == '=' || c == '"' || c == '\\' {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,58 @@
+/*
+ * Copyright 2022 ByteDance and/or its affiliates.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dyclog
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogfmtFormatter(t *testing.T) {
+	now := time.Date(2026, 10, 18, 15, 4, 5, 0, time.UTC)
+	l := &Entry{
+		Level:   INFO,
+		Time:    now,
+		Message: `user "bob" logged in`,
+		Context: context.Background(),
+		Caller:  GetCaller(1),
+	}
+	l.AddField("uid", 42)
+	l.AddField("query", "a=b")
+	l.AddField("empty", "")
+
+	f := NewLogfmtFormatter()
+	f.SetIP(false)
+	b, err := format(f, l)
+	assert.Nil(t, err)
+	assert.Equal(t, `time=2026-10-18T15:04:05Z level=INFO logid=- location=logfmt_formatter_test.go:34 `+
+		`message="user \"bob\" logged in" uid=42 query="a=b" empty=""`+"\n", string(b))
+}
+
+func TestLogfmtFormatterFixedKeyFields(t *testing.T) {
+	l := &Entry{Level: INFO, Message: "x", Context: context.Background()}
+	l.AddField("message", "dup")
+
+	f := NewLogfmtFormatter()
+	f.SetTimestamp(false)
+	f.SetIP(false)
+	b, err := format(f, l)
+	assert.Nil(t, err)
+	assert.Equal(t, "level=INFO logid=- message=x fields.message=dup\n", string(b))
+}
@@ -17,114 +17,291 @@
 package dyclog
 
 import (
-	"bytes"
 	"context"
 	"fmt"
+	"io"
+	"os"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
 const minCallDepth = 4
 
 type Logger struct {
-	writer    LogWriter
-	formatter Formatter
-	level     Level
-	callDepth int
+	// mu serializes replacing output, which is loaded without locking
+	// while logging.
+	mu         sync.Mutex
+	output     atomic.Value // *loggerOutput
+	level      atomic.Value // *AtomicLevel
+	callDepth  int
+	noCaller   bool
+	sampler    Sampler
+	processors []Processor
+	hooks      []Hook
+	levelHooks map[Level][]Hook
 
-	entryPool  sync.Pool
-	formatPool sync.Pool
+	entryPool sync.Pool
 }
 
 func NewDefaultLogger() *Logger {
-	return &Logger{
-		writer:    NewAsyncWriter(NewConsoleWriter(), false),
-		formatter: NewDefaultTextFormatter(),
-		level:     DEBUG,
+	return NewLogger(NewAsyncWriter(NewConsoleWriter(), false))
+}
+
+func NewLogger(writer LogWriter) *Logger {
+	logger := &Logger{
 		callDepth: minCallDepth,
 
 		entryPool: sync.Pool{
 			New: func() interface{} {
-				return log{}
+				return new(Entry)
 			},
 		},
 	}
+	logger.output.Store(&loggerOutput{formatter: NewDefaultTextFormatter(), writer: writer})
+	logger.level.Store(NewAtomicLevel(DEBUG))
+	return logger
 }
 
-func NewLogger(writer LogWriter) *Logger {
-	return &Logger{
-		writer:    writer,
-		formatter: NewDefaultTextFormatter(),
-		level:     DEBUG,
-		callDepth: minCallDepth,
+// loggerOutput is the formatter and the writer entries go to, it is never
+// modified but replaced as a whole.
+type loggerOutput struct {
+	// refs counts the entries being written to writer, it is first to be
+	// 64-bit aligned.
+	refs      int64
+	formatter Formatter
+	writer    LogWriter
+}
 
-		entryPool: sync.Pool{},
-		formatPool: sync.Pool{
-			New: func() interface{} {
-				return new(bytes.Buffer)
-			},
-		},
+func (logger *Logger) loadOutput() *loggerOutput {
+	return logger.output.Load().(*loggerOutput)
+}
+
+// acquireOutput returns the current output and keeps replace from returning
+// it until it is released.
+func (logger *Logger) acquireOutput() *loggerOutput {
+	for {
+		out := logger.loadOutput()
+		atomic.AddInt64(&out.refs, 1)
+		if logger.loadOutput() == out {
+			return out
+		}
+		atomic.AddInt64(&out.refs, -1)
 	}
 }
 
+func (out *loggerOutput) release() {
+	atomic.AddInt64(&out.refs, -1)
+}
+
+// swapOutput stores the output built from the current one by update and
+// returns the previous one.
+func (logger *Logger) swapOutput(update func(out *loggerOutput)) *loggerOutput {
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+	old := logger.loadOutput()
+	out := &loggerOutput{formatter: old.formatter, writer: old.writer}
+	update(out)
+	logger.output.Store(out)
+	return old
+}
+
 func (logger *Logger) GetWriter() LogWriter {
-	return logger.writer
+	return logger.loadOutput().writer
 }
 
+// SetWriter replaces the writer, entries being written when it is called
+// still go to the previous writer, which is not closed.
 func (logger *Logger) SetWriter(writer LogWriter) {
-	logger.writer = writer
+	logger.swapOutput(func(out *loggerOutput) {
+		out.writer = writer
+	})
 }
 
 func (logger *Logger) SetFormatter(formatter Formatter) {
-	logger.formatter = formatter
+	logger.swapOutput(func(out *loggerOutput) {
+		out.formatter = formatter
+	})
 }
 
+// replace replaces the formatter and the writer at once and returns the
+// previous writer, no entry is using it anymore once replace returns.
+func (logger *Logger) replace(formatter Formatter, writer LogWriter) LogWriter {
+	old := logger.swapOutput(func(out *loggerOutput) {
+		out.formatter = formatter
+		out.writer = writer
+	})
+	for atomic.LoadInt64(&old.refs) > 0 {
+		time.Sleep(time.Millisecond)
+	}
+	return old.writer
+}
+
+// SetLevel sets the level of the logger, it changes the AtomicLevel shared
+// with other loggers if one was set by SetAtomicLevel.
 func (logger *Logger) SetLevel(level Level) {
-	logger.level = level
+	logger.atomicLevel().SetLevel(level)
+}
+
+// SetAtomicLevel makes the logger use level, so it can be shared between
+// loggers and updated in one place. A nil level is ignored.
+func (logger *Logger) SetAtomicLevel(level *AtomicLevel) {
+	if level == nil {
+		return
+	}
+	logger.level.Store(level)
+}
+
+func (logger *Logger) GetLevel() Level {
+	return logger.atomicLevel().Level()
+}
+
+func (logger *Logger) atomicLevel() *AtomicLevel {
+	return logger.level.Load().(*AtomicLevel)
 }
 
 func (logger *Logger) SetCallDepth(depth int) {
 	logger.callDepth = depth
 }
 
+// SetCaller enables or disables capturing the caller of each entry,
+// the location field is omitted when disabled.
+func (logger *Logger) SetCaller(enable bool) {
+	logger.noCaller = !enable
+}
+
+// SetSampler sets the Sampler deciding whether an enabled entry is logged,
+// entries it drops are never built. nil disables sampling.
+func (logger *Logger) SetSampler(sampler Sampler) {
+	logger.sampler = sampler
+}
+
+// AddProcessor appends processors to the chain run between creating
+// an entry and formatting it, processors run in the order they are added.
+func (logger *Logger) AddProcessor(processors ...Processor) {
+	logger.processors = append(logger.processors, processors...)
+}
+
+// AddHook registers hook to be fired on each of hook.Levels(). The logger
+// takes ownership of hook, which is closed with the logger if it implements
+// io.Closer.
+func (logger *Logger) AddHook(hook Hook) {
+	if logger.levelHooks == nil {
+		logger.levelHooks = make(map[Level][]Hook)
+	}
+	logger.hooks = append(logger.hooks, hook)
+	for _, level := range hook.Levels() {
+		logger.levelHooks[level] = append(logger.levelHooks[level], hook)
+	}
+}
+
 func (logger *Logger) Flush() error {
-	return logger.writer.Flush()
+	return logger.GetWriter().Flush()
 }
 
+// Close closes the hooks implementing io.Closer and the writer, a hook shared
+// with another logger stops firing for that logger too.
 func (logger *Logger) Close() error {
-	return logger.writer.Close()
+	for _, hook := range logger.hooks {
+		if c, ok := hook.(io.Closer); ok {
+			_ = c.Close()
+		}
+	}
+	return logger.GetWriter().Close()
 }
 
-func (logger *Logger) newLog(ctx context.Context, level Level, format string, args ...interface{}) log {
-	l, ok := logger.entryPool.Get().(log)
-	if !ok {
-		l = log{}
-	}
+func (logger *Logger) newLog(ctx context.Context, level Level, format string, args ...interface{}) *Entry {
+	l := logger.entryPool.Get().(*Entry)
 	if ctx != nil {
-		l.context = ctx
+		l.Context = ctx
+	}
+	l.Time = time.Now()
+	l.Level = level
+	if len(args) == 0 {
+		l.Message = format
+	} else {
+		l.Message = fmt.Sprintf(format, resolveArgs(args)...)
+	}
+	if !logger.noCaller {
+		l.Caller = GetCaller(logger.callDepth)
 	}
-	l.time = time.Now()
-	l.level = level
-	l.message = fmt.Sprintf(format, args...)
-	l.caller = GetCaller(logger.callDepth)
 	return l
 }
 
-func (logger *Logger) releaseLog(entry log) {
+func (logger *Logger) releaseLog(entry *Entry) {
+	entry.reset()
 	logger.entryPool.Put(entry)
 }
 
+func (logger *Logger) process(entry *Entry) bool {
+	for _, p := range logger.processors {
+		if !p(entry) {
+			return false
+		}
+	}
+	return true
+}
+
+func (logger *Logger) fireHooks(entry *Entry) {
+	for _, hook := range logger.levelHooks[entry.Level] {
+		if err := hook.Fire(entry); err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "log hook fires error: %s\n", err)
+		}
+	}
+}
+
+// Enabled reports whether an entry of level would be logged, use it to guard
+// building expensive arguments.
+func (logger *Logger) Enabled(ctx context.Context, level Level) bool {
+	return logger.atomicLevel().Enabled(level)
+}
+
+// Logf logs a message formatted by fmt.Sprintf, format is written verbatim
+// when no args are given.
 func (logger *Logger) Logf(ctx context.Context, level Level, format string, args ...interface{}) {
-	if level < logger.level {
+	if !logger.Enabled(ctx, level) || !logger.sample(level) {
+		return
+	}
+	logger.write(logger.newLog(ctx, level, format, args...))
+}
+
+// Log logs msg verbatim, it is never interpreted as a format.
+func (logger *Logger) Log(ctx context.Context, level Level, msg string) {
+	if !logger.Enabled(ctx, level) || !logger.sample(level) {
+		return
+	}
+	logger.write(logger.newLog(ctx, level, msg))
+}
+
+func (logger *Logger) sample(level Level) bool {
+	return logger.sampler == nil || logger.sampler(level)
+}
+
+func (logger *Logger) write(l *Entry) {
+	defer logger.releaseLog(l)
+	if !logger.process(l) {
+		return
+	}
+	logger.fireHooks(l)
+
+	out := logger.acquireOutput()
+	defer out.release()
+	b := NewBuffer()
+	if err := out.formatter.Format(l, b); err != nil {
+		b.Free()
 		return
 	}
-	l := logger.newLog(ctx, level, format, args...)
-	b, err := logger.formatter.Format(l)
-	if err != nil {
+	b.SetLevel(l.Level)
+	if w, ok := out.writer.(BufferLogWriter); ok {
+		_ = w.WriteBuffer(b)
 		return
 	}
-	_ = logger.writer.Write(b)
-	logger.releaseLog(l)
+	_ = writeLevel(out.writer, l.Level, b.Bytes())
+	b.Free()
+}
+
+func (logger *Logger) Trace(format string, args ...interface{}) {
+	logger.Logf(context.Background(), TRACE, format, args...)
 }
 
 func (logger *Logger) Debug(format string, args ...interface{}) {
@@ -151,6 +328,10 @@ func (logger *Logger) Fatal(format string, args ...interface{}) {
 	logger.Logf(context.Background(), FATAL, format, args...)
 }
 
+func (logger *Logger) CtxTrace(ctx context.Context, format string, args ...interface{}) {
+	logger.Logf(ctx, TRACE, format, args...)
+}
+
 func (logger *Logger) CtxDebug(ctx context.Context, format string, args ...interface{}) {
 	logger.Logf(ctx, DEBUG, format, args...)
 }
@@ -174,3 +355,59 @@ func (logger *Logger) CtxError(ctx context.Context, format string, args ...inter
 func (logger *Logger) CtxFatal(ctx context.Context, format string, args ...interface{}) {
 	logger.Logf(ctx, FATAL, format, args...)
 }
+
+func (logger *Logger) TraceMsg(msg string) {
+	logger.Log(context.Background(), TRACE, msg)
+}
+
+func (logger *Logger) DebugMsg(msg string) {
+	logger.Log(context.Background(), DEBUG, msg)
+}
+
+func (logger *Logger) InfoMsg(msg string) {
+	logger.Log(context.Background(), INFO, msg)
+}
+
+func (logger *Logger) NoticeMsg(msg string) {
+	logger.Log(context.Background(), NOTICE, msg)
+}
+
+func (logger *Logger) WarnMsg(msg string) {
+	logger.Log(context.Background(), WARN, msg)
+}
+
+func (logger *Logger) ErrorMsg(msg string) {
+	logger.Log(context.Background(), ERROR, msg)
+}
+
+func (logger *Logger) FatalMsg(msg string) {
+	logger.Log(context.Background(), FATAL, msg)
+}
+
+func (logger *Logger) CtxTraceMsg(ctx context.Context, msg string) {
+	logger.Log(ctx, TRACE, msg)
+}
+
+func (logger *Logger) CtxDebugMsg(ctx context.Context, msg string) {
+	logger.Log(ctx, DEBUG, msg)
+}
+
+func (logger *Logger) CtxInfoMsg(ctx context.Context, msg string) {
+	logger.Log(ctx, INFO, msg)
+}
+
+func (logger *Logger) CtxNoticeMsg(ctx context.Context, msg string) {
+	logger.Log(ctx, NOTICE, msg)
+}
+
+func (logger *Logger) CtxWarnMsg(ctx context.Context, msg string) {
+	logger.Log(ctx, WARN, msg)
+}
+
+func (logger *Logger) CtxErrorMsg(ctx context.Context, msg string) {
+	logger.Log(ctx, ERROR, msg)
+}
+
+func (logger *Logger) CtxFatalMsg(ctx context.Context, msg string) {
+	logger.Log(ctx, FATAL, msg)
+}
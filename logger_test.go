@@ -177,3 +177,91 @@ func TestFatal(t *testing.T) {
 	assert.Equal(t, GetLogger().GetWriter().(*BufferWriter).String(), "FATAL 1234567890 logger_test.go:176 "+ip+" err: params is not valid\n")
 	GetLogger().GetWriter().(*BufferWriter).Reset()
 }
+
+func TestMsg(t *testing.T) {
+	logger := NewLogger(new(BufferWriter))
+	logger.SetFormatter(NewTextFormatter(false))
+	ip := GetLocalIP()
+
+	logger.InfoMsg("progress: 100%d")
+	assert.Equal(t, "INFO - logger_test.go:186 "+ip+" progress: 100%d\n", logger.GetWriter().(*BufferWriter).String())
+	logger.GetWriter().(*BufferWriter).Reset()
+
+	ctx := InjectLogIDToCtx(context.Background(), "1234567890")
+	logger.CtxErrorMsg(ctx, "100%s")
+	assert.Equal(t, "ERROR 1234567890 logger_test.go:191 "+ip+" 100%s\n", logger.GetWriter().(*BufferWriter).String())
+	logger.GetWriter().(*BufferWriter).Reset()
+
+	logger.Warn("no args: %d%%")
+	assert.Equal(t, "WARN - logger_test.go:195 "+ip+" no args: %d%%\n", logger.GetWriter().(*BufferWriter).String())
+	logger.GetWriter().(*BufferWriter).Reset()
+
+	SetWriter(new(BufferWriter))
+	SetFormatter(NewTextFormatter(false))
+	NoticeMsg("50%")
+	assert.Equal(t, "NOTICE - logger_test.go:201 "+ip+" 50%\n", GetLogger().GetWriter().(*BufferWriter).String())
+	GetLogger().GetWriter().(*BufferWriter).Reset()
+
+	CtxDebugMsg(ctx, "%v")
+	assert.Equal(t, "DEBUG 1234567890 logger_test.go:205 "+ip+" %v\n", GetLogger().GetWriter().(*BufferWriter).String())
+	GetLogger().GetWriter().(*BufferWriter).Reset()
+}
+
+func TestSetDefault(t *testing.T) {
+	old := GetLogger()
+	defer SetDefault(old)
+
+	logger := NewLogger(new(BufferWriter))
+	logger.SetFormatter(NewTextFormatter(false))
+	logger.SetCaller(false)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			_ = GetLogger().GetLevel()
+		}
+	}()
+	SetDefault(logger)
+	SetDefault(nil)
+	<-done
+
+	assert.Equal(t, logger, GetLogger())
+	InfoMsg("shared")
+	assert.Equal(t, "INFO - "+GetLocalIP()+" shared\n", logger.GetWriter().(*BufferWriter).String())
+}
+
+type levelRecorder struct {
+	BufferWriter
+	levels []Level
+}
+
+func (w *levelRecorder) WriteLevel(level Level, log []byte) error {
+	w.levels = append(w.levels, level)
+	return w.Write(log)
+}
+
+func TestWriteLevel(t *testing.T) {
+	direct, queued := new(levelRecorder), new(levelRecorder)
+	async := NewAsyncWriter(queued, false)
+	logger := NewLogger(NewMultiWriter(direct, async))
+	logger.SetCaller(false)
+
+	logger.InfoMsg("info")
+	logger.ErrorMsg("error")
+	assert.Nil(t, logger.Flush())
+	assert.Equal(t, []Level{INFO, ERROR}, direct.levels)
+	assert.Equal(t, []Level{INFO, ERROR}, queued.levels)
+	assert.Equal(t, direct.String(), queued.String())
+	assert.Nil(t, async.Close())
+}
+
+func TestEntryResetClearsFields(t *testing.T) {
+	entry := &Entry{Message: "x"}
+	entry.AddField("body", "payload")
+	entry.AddField("ok", true)
+	fields := entry.Fields
+	entry.reset()
+	assert.Equal(t, 0, len(entry.Fields))
+	assert.Equal(t, []Field{{}, {}}, fields)
+}
@@ -0,0 +1,75 @@
+/*
+ * Copyright 2022 ByteDance and/or its affiliates.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dyclog
+
+// MultiWriter writes every entry to all of its writers in order.
+type MultiWriter struct {
+	writers []LogWriter
+}
+
+// NewMultiWriter creates a MultiWriter, it returns the writer itself if
+// only one is given.
+func NewMultiWriter(writers ...LogWriter) LogWriter {
+	if len(writers) == 1 {
+		return writers[0]
+	}
+	return &MultiWriter{
+		writers: writers,
+	}
+}
+
+// Write writes log to all writers and returns the first error.
+func (mw *MultiWriter) Write(log []byte) error {
+	var firstErr error
+	for _, w := range mw.writers {
+		if err := w.Write(log); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// WriteLevel writes log to all writers with its level and returns the first error.
+func (mw *MultiWriter) WriteLevel(level Level, log []byte) error {
+	var firstErr error
+	for _, w := range mw.writers {
+		if err := writeLevel(w, level, log); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+func (mw *MultiWriter) Flush() error {
+	var firstErr error
+	for _, w := range mw.writers {
+		if err := w.Flush(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+func (mw *MultiWriter) Close() error {
+	var firstErr error
+	for _, w := range mw.writers {
+		if err := w.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
@@ -0,0 +1,76 @@
+/*
+ * Copyright 2022 ByteDance and/or its affiliates.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dyclog
+
+import (
+	"os"
+	"unicode/utf8"
+)
+
+const (
+	fieldKeyHost = "host"
+	fieldKeyPID  = "pid"
+)
+
+// Processor enriches, transforms or drops an Entry before it is formatted,
+// returning false drops the entry and skips the remaining processors.
+type Processor func(entry *Entry) (keep bool)
+
+// NewHostInfoProcessor creates a Processor which adds the host name and
+// the process id to every entry.
+func NewHostInfoProcessor() Processor {
+	host, _ := os.Hostname()
+	var pid interface{} = os.Getpid()
+	return func(entry *Entry) bool {
+		entry.AddField(fieldKeyHost, host)
+		entry.AddField(fieldKeyPID, pid)
+		return true
+	}
+}
+
+// NewTruncateProcessor creates a Processor which truncates messages longer
+// than max bytes, it never splits a multi-byte character. A negative max
+// is treated as 0.
+func NewTruncateProcessor(max int) Processor {
+	if max < 0 {
+		max = 0
+	}
+	return func(entry *Entry) bool {
+		if len(entry.Message) <= max {
+			return true
+		}
+		n := max
+		for n > 0 && !utf8.RuneStart(entry.Message[n]) {
+			n--
+		}
+		entry.Message = entry.Message[:n] + "..."
+		return true
+	}
+}
+
+// NewLevelFilterProcessor creates a Processor which drops entries whose
+// level is not in levels.
+func NewLevelFilterProcessor(levels ...Level) Processor {
+	return func(entry *Entry) bool {
+		for _, level := range levels {
+			if entry.Level == level {
+				return true
+			}
+		}
+		return false
+	}
+}
@@ -0,0 +1,77 @@
+/*
+ * Copyright 2022 ByteDance and/or its affiliates.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dyclog
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProcessorChain(t *testing.T) {
+	logger := NewLogger(new(BufferWriter))
+	calls := make([]string, 0)
+	logger.AddProcessor(func(entry *Entry) bool {
+		calls = append(calls, "first")
+		entry.Message = strings.ToUpper(entry.Message)
+		return entry.Level >= INFO
+	}, func(entry *Entry) bool {
+		calls = append(calls, "second")
+		return true
+	})
+
+	logger.Debug("dropped")
+	assert.Equal(t, []string{"first"}, calls)
+	assert.Equal(t, "", logger.GetWriter().(*BufferWriter).String())
+
+	logger.Info("kept")
+	assert.Equal(t, []string{"first", "first", "second"}, calls)
+	assert.True(t, strings.HasSuffix(logger.GetWriter().(*BufferWriter).String(), " KEPT\n"))
+}
+
+func TestHostInfoProcessor(t *testing.T) {
+	logger := NewLogger(new(BufferWriter))
+	logger.AddProcessor(NewHostInfoProcessor())
+	logger.Info("hello")
+	host, _ := os.Hostname()
+	assert.True(t, strings.HasSuffix(logger.GetWriter().(*BufferWriter).String(),
+		" hello host="+host+" pid="+strconv.Itoa(os.Getpid())+"\n"))
+}
+
+func TestTruncateProcessor(t *testing.T) {
+	p := NewTruncateProcessor(4)
+	entry := &Entry{Message: "ab你好"}
+	assert.True(t, p(entry))
+	assert.Equal(t, "ab...", entry.Message)
+
+	entry.Message = "abc"
+	assert.True(t, p(entry))
+	assert.Equal(t, "abc", entry.Message)
+
+	entry.Message = "abc"
+	assert.True(t, NewTruncateProcessor(-1)(entry))
+	assert.Equal(t, "...", entry.Message)
+}
+
+func TestLevelFilterProcessor(t *testing.T) {
+	p := NewLevelFilterProcessor(ERROR, FATAL)
+	assert.False(t, p(&Entry{Level: WARN}))
+	assert.True(t, p(&Entry{Level: ERROR}))
+}
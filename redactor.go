@@ -0,0 +1,256 @@
+/*
+ * Copyright 2022 ByteDance and/or its affiliates.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dyclog
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const maskChar = '*'
+
+// RedactRule describes one kind of sensitive data to be masked.
+type RedactRule struct {
+	Name    string
+	Pattern *regexp.Regexp
+	// Validate, if set, is called for every match and the match is kept
+	// untouched when it returns false.
+	Validate func(match string) bool
+	// Mask returns the replacement of a match.
+	Mask func(match string) string
+}
+
+var (
+	// RuleMobile masks mainland China mobile numbers, e.g. 138****1234.
+	RuleMobile = RedactRule{
+		Name:    "mobile",
+		Pattern: regexp.MustCompile(`\b1[3-9]\d{9}\b`),
+		Mask:    MaskMiddle(3, 4),
+	}
+	// RuleIDCard masks 18-digit resident identity card numbers.
+	RuleIDCard = RedactRule{
+		Name:    "id_card",
+		Pattern: regexp.MustCompile(`\b[1-9]\d{5}(?:18|19|20)\d{2}(?:0[1-9]|1[0-2])(?:0[1-9]|[12]\d|3[01])\d{3}[\dXx]\b`),
+		Mask:    MaskMiddle(6, 4),
+	}
+	// RuleEmail masks the local part of email addresses.
+	RuleEmail = RedactRule{
+		Name:    "email",
+		Pattern: regexp.MustCompile(`\b[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}\b`),
+		Mask:    maskEmail,
+	}
+	// RuleBearerToken masks the credential of bearer authorization values.
+	RuleBearerToken = RedactRule{
+		Name:    "bearer_token",
+		Pattern: regexp.MustCompile(`(?i)\bbearer\s+[A-Za-z0-9\-._~+/]+=*`),
+		Mask:    maskBearer,
+	}
+	// RuleBankCard masks bank card numbers which pass the Luhn check.
+	RuleBankCard = RedactRule{
+		Name:     "bank_card",
+		Pattern:  regexp.MustCompile(`\b\d{13,19}\b`),
+		Validate: luhnValid,
+		Mask:     MaskMiddle(4, 4),
+	}
+)
+
+// Redactor masks sensitive data in log messages and fields, register its
+// Process method on a Logger to apply it before formatting.
+type Redactor struct {
+	rules  []RedactRule
+	fields []string
+
+	keyPattern   *regexp.Regexp
+	fieldPattern *regexp.Regexp
+}
+
+// NewDefaultRedactor creates a Redactor with all built-in rules and
+// masks the values of password and token fields.
+func NewDefaultRedactor() *Redactor {
+	r := NewRedactor(RuleIDCard, RuleBankCard, RuleMobile, RuleEmail, RuleBearerToken)
+	r.AddFields("password", "token")
+	return r
+}
+
+// NewRedactor creates a Redactor applying the given rules in order.
+func NewRedactor(rules ...RedactRule) *Redactor {
+	return &Redactor{
+		rules: rules,
+	}
+}
+
+// AddRule appends a rule, it is applied after the existing ones.
+func (r *Redactor) AddRule(rule RedactRule) {
+	r.rules = append(r.rules, rule)
+}
+
+// AddPattern appends a rule built from a regular expression, every match
+// keeps its first head and last tail characters.
+func (r *Redactor) AddPattern(name, expr string, head, tail int) error {
+	pattern, err := regexp.Compile(expr)
+	if err != nil {
+		return err
+	}
+	r.AddRule(RedactRule{
+		Name:    name,
+		Pattern: pattern,
+		Mask:    MaskMiddle(head, tail),
+	})
+	return nil
+}
+
+// AddFields masks the whole value of key-value pairs like `password=xxx`,
+// `token: xxx` or `"password":"xxx"` and of entry fields whose key
+// contains one of names, ignoring case.
+func (r *Redactor) AddFields(names ...string) {
+	r.fields = append(r.fields, names...)
+	if len(r.fields) == 0 {
+		return
+	}
+	quoted := make([]string, 0, len(r.fields))
+	for _, name := range r.fields {
+		quoted = append(quoted, regexp.QuoteMeta(name))
+	}
+	key := `(?i)(?:` + strings.Join(quoted, "|") + `)`
+	r.keyPattern = regexp.MustCompile(key)
+	// a quoted value is masked up to its closing quote, others up to
+	// the next separator.
+	r.fieldPattern = regexp.MustCompile(`("?[\w.-]*` + key + `[\w.-]*"?\s*[:=]\s*)(?:(")(?:[^"\\]|\\.)*"|[^"\s,&;}]+)`)
+}
+
+// Redact returns s with all sensitive data masked.
+func (r *Redactor) Redact(s string) string {
+	if r.fieldPattern != nil {
+		s = r.fieldPattern.ReplaceAllString(s, "${1}${2}******${2}")
+	}
+	for _, rule := range r.rules {
+		s = rule.apply(s)
+	}
+	return s
+}
+
+// Process is a Processor which redacts the message and the fields of entry.
+// LogValuer values are resolved first, errors, fmt.Stringers, byte slices and
+// integers are redacted as text and replaced by the redacted text if any of
+// it is masked.
+func (r *Redactor) Process(entry *Entry) bool {
+	entry.Message = r.Redact(entry.Message)
+	for i := range entry.Fields {
+		field := &entry.Fields[i]
+		if r.isSensitiveField(field.Key) {
+			field.Value = "******"
+			continue
+		}
+		field.Value = resolveLogValue(field.Value)
+		if text, ok := redactableText(field.Value); ok {
+			if redacted := r.Redact(text); redacted != text {
+				field.Value = redacted
+			}
+		}
+	}
+	return true
+}
+
+// redactableText returns the text of the values Process redacts.
+func redactableText(v interface{}) (string, bool) {
+	switch v := v.(type) {
+	case string:
+		return v, true
+	case []byte:
+		return string(v), true
+	case error:
+		return v.Error(), true
+	case fmt.Stringer:
+		return v.String(), true
+	case int:
+		return strconv.Itoa(v), true
+	case int32:
+		return strconv.FormatInt(int64(v), 10), true
+	case int64:
+		return strconv.FormatInt(v, 10), true
+	case uint:
+		return strconv.FormatUint(uint64(v), 10), true
+	case uint32:
+		return strconv.FormatUint(uint64(v), 10), true
+	case uint64:
+		return strconv.FormatUint(v, 10), true
+	}
+	return "", false
+}
+
+func (r *Redactor) isSensitiveField(key string) bool {
+	return r.keyPattern != nil && r.keyPattern.MatchString(key)
+}
+
+func (rule RedactRule) apply(s string) string {
+	if rule.Pattern == nil || rule.Mask == nil {
+		return s
+	}
+	return rule.Pattern.ReplaceAllStringFunc(s, func(match string) string {
+		if rule.Validate != nil && !rule.Validate(match) {
+			return match
+		}
+		return rule.Mask(match)
+	})
+}
+
+// MaskMiddle returns a mask function which keeps the first head and the
+// last tail characters and replaces the rest with '*'.
+func MaskMiddle(head, tail int) func(string) string {
+	return func(s string) string {
+		runes := []rune(s)
+		if head+tail >= len(runes) {
+			return strings.Repeat(string(maskChar), len(runes))
+		}
+		for i := head; i < len(runes)-tail; i++ {
+			runes[i] = maskChar
+		}
+		return string(runes)
+	}
+}
+
+func maskEmail(s string) string {
+	at := strings.LastIndexByte(s, '@')
+	if at <= 0 {
+		return s
+	}
+	return MaskMiddle(1, 0)(s[:at]) + s[at:]
+}
+
+func maskBearer(s string) string {
+	fields := strings.Fields(s)
+	return fields[0] + " ******"
+}
+
+func luhnValid(number string) bool {
+	sum := 0
+	double := false
+	for i := len(number) - 1; i >= 0; i-- {
+		d := int(number[i] - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
@@ -0,0 +1,91 @@
+/*
+ * Copyright 2022 ByteDance and/or its affiliates.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dyclog
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedactorBuiltinRules(t *testing.T) {
+	r := NewDefaultRedactor()
+	data := map[string]string{
+		"phone 13812341234 called":            "phone 138****1234 called",
+		"id 110101199003071234 checked":       "id 110101********1234 checked",
+		"mail zhangsan@example.com":           "mail z*******@example.com",
+		"Authorization: Bearer abc.def-ghi==": "Authorization: Bearer ******",
+		"card 4111111111111111 paid":          "card 4111********1111 paid",
+		"order 4111111111111112 paid":         "order 4111111111111112 paid",
+		"password=123456&user=bob":            "password=******&user=bob",
+		`{"access_token":"xyz","id":1}`:       `{"access_token":"******","id":1}`,
+		`password="a b" user=bob`:             `password="******" user=bob`,
+		`{"Password":"a \"b\" c"}`:            `{"Password":"******"}`,
+		"token_id=42 ok":                      "token_id=****** ok",
+		"count 5 tokens":                      "count 5 tokens",
+	}
+	for in, want := range data {
+		assert.Equal(t, want, r.Redact(in))
+	}
+}
+
+func TestRedactorCustomPattern(t *testing.T) {
+	r := NewRedactor()
+	assert.NotNil(t, r.AddPattern("broken", `(`, 1, 2))
+	assert.Nil(t, r.AddPattern("passport", `\bE\d{8}\b`, 1, 2))
+	assert.Equal(t, "passport E******78", r.Redact("passport E12345678"))
+}
+
+func TestLoggerRedactor(t *testing.T) {
+	logger := NewLogger(new(BufferWriter))
+	logger.AddProcessor(func(entry *Entry) bool {
+		entry.AddField("token", "secret")
+		return true
+	}, NewDefaultRedactor().Process)
+	logger.Info("user %s login", "13812341234")
+	assert.Contains(t, logger.GetWriter().(*BufferWriter).String(), "user 138****1234 login token=******\n")
+}
+
+func TestRedactorProcessValues(t *testing.T) {
+	calls := 0
+	entry := &Entry{Fields: []Field{
+		{Key: "err", Value: errors.New("Authorization: Bearer abcdef")},
+		{Key: "lazy", Value: countingValuer{calls: &calls, value: "13812341234"}},
+		{Key: "phone", Value: int64(13812341234)},
+		{Key: "body", Value: []byte("mail zhangsan@example.com")},
+		{Key: "count", Value: 42},
+		{Key: "ok", Value: true},
+	}}
+	assert.True(t, NewDefaultRedactor().Process(entry))
+	assert.Equal(t, []Field{
+		{Key: "err", Value: "Authorization: Bearer ******"},
+		{Key: "lazy", Value: "138****1234"},
+		{Key: "phone", Value: "138****1234"},
+		{Key: "body", Value: "mail z*******@example.com"},
+		{Key: "count", Value: 42},
+		{Key: "ok", Value: true},
+	}, entry.Fields)
+	assert.Equal(t, 1, calls)
+}
+
+func TestRedactorNoFields(t *testing.T) {
+	r := NewRedactor()
+	r.AddFields()
+	assert.Equal(t, "a=1 b=2", r.Redact("a=1 b=2"))
+	assert.False(t, r.isSensitiveField("a"))
+}
@@ -0,0 +1,504 @@
+/*
+ * Copyright 2022 ByteDance and/or its affiliates.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dyclog
+
+import (
+	"compress/gzip"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Options are the generic options passed to writer and formatter factories,
+// usually decoded from a JSON or YAML config.
+type Options map[string]interface{}
+
+// WriterFactory creates a LogWriter from options.
+type WriterFactory func(options Options) (LogWriter, error)
+
+// FormatterFactory creates a Formatter from options.
+type FormatterFactory func(options Options) (Formatter, error)
+
+var (
+	registryMu         sync.RWMutex
+	writerFactories    = make(map[string]WriterFactory)
+	formatterFactories = make(map[string]FormatterFactory)
+)
+
+// RegisterWriter makes a writer selectable by name in configs, it is meant
+// to be called in init functions and panics if name is already registered.
+func RegisterWriter(name string, factory WriterFactory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	name = strings.ToLower(name)
+	if factory == nil {
+		panic("dyclog: RegisterWriter factory is nil")
+	}
+	if _, dup := writerFactories[name]; dup {
+		panic("dyclog: RegisterWriter called twice for " + name)
+	}
+	writerFactories[name] = factory
+}
+
+// RegisterFormatter makes a formatter selectable by name in configs, it is
+// meant to be called in init functions and panics if name is already registered.
+func RegisterFormatter(name string, factory FormatterFactory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	name = strings.ToLower(name)
+	if factory == nil {
+		panic("dyclog: RegisterFormatter factory is nil")
+	}
+	if _, dup := formatterFactories[name]; dup {
+		panic("dyclog: RegisterFormatter called twice for " + name)
+	}
+	formatterFactories[name] = factory
+}
+
+// NewWriter creates a writer by its registered name.
+func NewWriter(name string, options Options) (LogWriter, error) {
+	registryMu.RLock()
+	factory, ok := writerFactories[strings.ToLower(name)]
+	registryMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown writer type %q", name)
+	}
+	w, err := factory(options)
+	if err != nil {
+		return nil, fmt.Errorf("create writer %s: %w", name, err)
+	}
+	return w, nil
+}
+
+// NewFormatter creates a formatter by its registered name.
+func NewFormatter(name string, options Options) (Formatter, error) {
+	registryMu.RLock()
+	factory, ok := formatterFactories[strings.ToLower(name)]
+	registryMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown formatter type %q", name)
+	}
+	f, err := factory(options)
+	if err != nil {
+		return nil, fmt.Errorf("create formatter %s: %w", name, err)
+	}
+	return f, nil
+}
+
+// Has reports whether key is set.
+func (o Options) Has(key string) bool {
+	_, ok := o[key]
+	return ok
+}
+
+// String returns the string option key, or "" if it is not set.
+func (o Options) String(key string) (string, error) {
+	v, ok := o[key]
+	if !ok || v == nil {
+		return "", nil
+	}
+	s, ok := v.(string)
+	if !ok {
+		return "", fmt.Errorf("option %s must be a string, got %T", key, v)
+	}
+	return s, nil
+}
+
+// Bool returns the bool option key, or false if it is not set.
+func (o Options) Bool(key string) (bool, error) {
+	v, ok := o[key]
+	if !ok || v == nil {
+		return false, nil
+	}
+	b, ok := v.(bool)
+	if !ok {
+		return false, fmt.Errorf("option %s must be a bool, got %T", key, v)
+	}
+	return b, nil
+}
+
+// Int returns the integer option key, or 0 if it is not set. Integral
+// float64 values decoded from JSON are accepted.
+func (o Options) Int(key string) (int, error) {
+	v, ok := o[key]
+	if !ok || v == nil {
+		return 0, nil
+	}
+	switch n := v.(type) {
+	case int:
+		return n, nil
+	case int64:
+		return int(n), nil
+	case uint64:
+		return int(n), nil
+	case float64:
+		if n == math.Trunc(n) {
+			return int(n), nil
+		}
+	}
+	return 0, fmt.Errorf("option %s must be an integer, got %v", key, v)
+}
+
+// Strings returns the string list option key, or nil if it is not set.
+// A single string is accepted as a list of one.
+func (o Options) Strings(key string) ([]string, error) {
+	v, ok := o[key]
+	if !ok || v == nil {
+		return nil, nil
+	}
+	switch l := v.(type) {
+	case string:
+		return []string{l}, nil
+	case []string:
+		return l, nil
+	case []interface{}:
+		strs := make([]string, 0, len(l))
+		for _, e := range l {
+			s, ok := e.(string)
+			if !ok {
+				return nil, fmt.Errorf("option %s must be a list of strings, got %T", key, e)
+			}
+			strs = append(strs, s)
+		}
+		return strs, nil
+	}
+	return nil, fmt.Errorf("option %s must be a list of strings, got %T", key, v)
+}
+
+// Sub returns the nested options key, or nil if it is not set.
+func (o Options) Sub(key string) (Options, error) {
+	v, ok := o[key]
+	if !ok || v == nil {
+		return nil, nil
+	}
+	switch m := v.(type) {
+	case Options:
+		return m, nil
+	case map[string]interface{}:
+		return m, nil
+	}
+	return nil, fmt.Errorf("option %s must be a map, got %T", key, v)
+}
+
+func init() {
+	RegisterWriter("console", newConsoleWriterFromOptions)
+	RegisterWriter("file", newFileWriterFromOptions)
+	RegisterWriter("async", newAsyncWriterFromOptions)
+	RegisterWriter("buffer", func(options Options) (LogWriter, error) {
+		return new(BufferWriter), nil
+	})
+
+	RegisterFormatter("text", newTextFormatterFromOptions)
+	RegisterFormatter("json", func(options Options) (Formatter, error) {
+		f := NewJSONFormatter()
+		return f, f.fieldOptions.apply(options)
+	})
+	RegisterFormatter("logfmt", func(options Options) (Formatter, error) {
+		f := NewLogfmtFormatter()
+		return f, f.fieldOptions.apply(options)
+	})
+}
+
+// newConsoleWriterFromOptions accepts output, stdout or stderr.
+func newConsoleWriterFromOptions(options Options) (LogWriter, error) {
+	output, err := options.String("output")
+	if err != nil {
+		return nil, err
+	}
+	switch strings.ToLower(output) {
+	case "", "stdout":
+		return NewConsoleWriter(), nil
+	case "stderr":
+		return NewConsoleWriterTo(os.Stderr), nil
+	}
+	return nil, fmt.Errorf("unknown console output %q", output)
+}
+
+// newFileWriterFromOptions accepts filename, rotation, daily, hourly,
+// minutely or a duration like 6h, location as an IANA time zone name,
+// time_layout, filename_pattern, limit_files,
+// max_file_size and max_total_size in bytes, max_age as a duration like 168h,
+// compress, compress_level, buffer_size, degraded_retry, reopen_check and
+// flush_interval as durations, sync, never, error, always or a duration, and
+// reopen_signals, a list of names like SIGHUP or SIGUSR1.
+func newFileWriterFromOptions(options Options) (LogWriter, error) {
+	filename, err := options.String("filename")
+	if err != nil {
+		return nil, err
+	}
+	if filename == "" {
+		return nil, fmt.Errorf("option filename is required")
+	}
+	rotation, err := options.String("rotation")
+	if err != nil {
+		return nil, err
+	}
+	var window RotationWindow
+	var fileOptions []FileOption
+	switch strings.ToLower(rotation) {
+	case "", "daily":
+		window = Daily
+	case "hourly":
+		window = Hourly
+	case "minutely":
+		window = Minutely
+	default:
+		d, err := time.ParseDuration(rotation)
+		if err != nil {
+			return nil, fmt.Errorf("unknown rotation %q", rotation)
+		}
+		fileOptions = append(fileOptions, SetRotationInterval(d))
+	}
+	location, err := options.String("location")
+	if err != nil {
+		return nil, err
+	}
+	if location != "" {
+		loc, err := time.LoadLocation(location)
+		if err != nil {
+			return nil, err
+		}
+		fileOptions = append(fileOptions, SetLocation(loc))
+	}
+	layout, err := options.String("time_layout")
+	if err != nil {
+		return nil, err
+	}
+	if layout != "" {
+		fileOptions = append(fileOptions, SetTimeLayout(layout))
+	}
+	pattern, err := options.String("filename_pattern")
+	if err != nil {
+		return nil, err
+	}
+	if pattern != "" {
+		fileOptions = append(fileOptions, SetFilenamePattern(pattern))
+	}
+	limit, err := options.Int("limit_files")
+	if err != nil {
+		return nil, err
+	}
+	maxSize, err := options.Int("max_file_size")
+	if err != nil {
+		return nil, err
+	}
+	maxTotalSize, err := options.Int("max_total_size")
+	if err != nil {
+		return nil, err
+	}
+	maxAge, err := options.String("max_age")
+	if err != nil {
+		return nil, err
+	}
+	fileOptions = append(fileOptions, SetLimitFiles(limit), SetMaxFileSize(int64(maxSize)), SetMaxTotalSize(int64(maxTotalSize)))
+	if maxAge != "" {
+		d, err := time.ParseDuration(maxAge)
+		if err != nil {
+			return nil, err
+		}
+		fileOptions = append(fileOptions, SetMaxAge(d))
+	}
+	compress, err := options.Bool("compress")
+	if err != nil {
+		return nil, err
+	}
+	if compress {
+		level := gzip.DefaultCompression
+		if options.Has("compress_level") {
+			if level, err = options.Int("compress_level"); err != nil {
+				return nil, err
+			}
+		}
+		fileOptions = append(fileOptions, SetCompress(level))
+	}
+	retry, err := options.String("degraded_retry")
+	if err != nil {
+		return nil, err
+	}
+	if retry != "" {
+		d, err := time.ParseDuration(retry)
+		if err != nil {
+			return nil, err
+		}
+		fileOptions = append(fileOptions, SetDegradedMode(d))
+	}
+	if options.Has("buffer_size") {
+		size, err := options.Int("buffer_size")
+		if err != nil {
+			return nil, err
+		}
+		fileOptions = append(fileOptions, SetBufferSize(size))
+	}
+	flush, err := options.String("flush_interval")
+	if err != nil {
+		return nil, err
+	}
+	if flush != "" {
+		d, err := time.ParseDuration(flush)
+		if err != nil {
+			return nil, err
+		}
+		fileOptions = append(fileOptions, SetFlushInterval(d))
+	}
+	syncPolicy, err := options.String("sync")
+	if err != nil {
+		return nil, err
+	}
+	switch strings.ToLower(syncPolicy) {
+	case "", "never":
+	case "error":
+		fileOptions = append(fileOptions, SetSyncPolicy(SyncOnError))
+	case "always":
+		fileOptions = append(fileOptions, SetSyncPolicy(SyncAlways))
+	default:
+		d, err := time.ParseDuration(syncPolicy)
+		if err != nil {
+			return nil, fmt.Errorf("unknown sync policy %q", syncPolicy)
+		}
+		fileOptions = append(fileOptions, SetSyncPolicy(SyncPeriodically), SetSyncInterval(d))
+	}
+	reopen, err := options.String("reopen_check")
+	if err != nil {
+		return nil, err
+	}
+	if reopen != "" {
+		d, err := time.ParseDuration(reopen)
+		if err != nil {
+			return nil, err
+		}
+		fileOptions = append(fileOptions, SetReopenCheck(d))
+	}
+	signals, err := options.Strings("reopen_signals")
+	if err != nil {
+		return nil, err
+	}
+	if len(signals) > 0 {
+		sigs := make([]os.Signal, 0, len(signals))
+		for _, name := range signals {
+			sig, ok := signalNames[strings.TrimPrefix(strings.ToUpper(name), "SIG")]
+			if !ok {
+				return nil, fmt.Errorf("unknown reopen signal %q", name)
+			}
+			sigs = append(sigs, sig)
+		}
+		fileOptions = append(fileOptions, SetReopenSignals(sigs...))
+	}
+	w, err := OpenFileWriter(filename, window, fileOptions...)
+	if err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// newAsyncWriterFromOptions accepts omit, queue_size and writer, the options
+// of the wrapped writer with its name in type.
+func newAsyncWriterFromOptions(options Options) (LogWriter, error) {
+	omit, err := options.Bool("omit")
+	if err != nil {
+		return nil, err
+	}
+	size, err := options.Int("queue_size")
+	if err != nil {
+		return nil, err
+	}
+	if size <= 0 {
+		size = defaultAsyncQueueSize
+	}
+	inner, err := options.Sub("writer")
+	if err != nil {
+		return nil, err
+	}
+	if inner == nil {
+		return nil, fmt.Errorf("option writer is required")
+	}
+	name, err := inner.String("type")
+	if err != nil {
+		return nil, err
+	}
+	w, err := NewWriter(name, inner)
+	if err != nil {
+		return nil, err
+	}
+	return NewAsyncWriterSize(w, omit, size), nil
+}
+
+// newTextFormatterFromOptions accepts color, quote and the field options.
+func newTextFormatterFromOptions(options Options) (Formatter, error) {
+	color, err := options.Bool("color")
+	if err != nil {
+		return nil, err
+	}
+	quote, err := options.Bool("quote")
+	if err != nil {
+		return nil, err
+	}
+	f := NewTextFormatter(color)
+	f.SetQuote(quote)
+	return f, f.fieldOptions.apply(options)
+}
+
+// apply sets the field options from timestamp, timestamp_format, disable_ip,
+// function, caller_format, short, package or full, and caller_trim_prefix.
+func (o *fieldOptions) apply(options Options) error {
+	if options.Has("timestamp") {
+		timestamp, err := options.Bool("timestamp")
+		if err != nil {
+			return err
+		}
+		o.SetTimestamp(timestamp)
+	}
+	layout, err := options.String("timestamp_format")
+	if err != nil {
+		return err
+	}
+	if layout != "" {
+		o.SetTimestampFormat(layout)
+	}
+	disableIP, err := options.Bool("disable_ip")
+	if err != nil {
+		return err
+	}
+	o.SetIP(!disableIP)
+	function, err := options.Bool("function")
+	if err != nil {
+		return err
+	}
+	o.SetFunction(function)
+	callerFormat, err := options.String("caller_format")
+	if err != nil {
+		return err
+	}
+	switch strings.ToLower(callerFormat) {
+	case "", "short":
+		o.SetCallerFormat(CallerShortFile)
+	case "package":
+		o.SetCallerFormat(CallerPackageFile)
+	case "full":
+		o.SetCallerFormat(CallerFullPath)
+	default:
+		return fmt.Errorf("unknown caller format %q", callerFormat)
+	}
+	prefix, err := options.String("caller_trim_prefix")
+	if err != nil {
+		return err
+	}
+	o.SetCallerTrimPrefix(prefix)
+	return nil
+}
@@ -0,0 +1,136 @@
+/*
+ * Copyright 2022 ByteDance and/or its affiliates.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dyclog
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type nopFormatter struct {
+	prefix string
+}
+
+func (f *nopFormatter) Format(entry *Entry, b *Buffer) error {
+	_, _ = b.WriteString(f.prefix + entry.Message + "\n")
+	return nil
+}
+
+func TestRegistry(t *testing.T) {
+	t.Cleanup(func() {
+		registryMu.Lock()
+		delete(formatterFactories, "test-prefix")
+		registryMu.Unlock()
+	})
+	RegisterFormatter("test-prefix", func(options Options) (Formatter, error) {
+		prefix, err := options.String("prefix")
+		return &nopFormatter{prefix: prefix}, err
+	})
+	assert.Panics(t, func() {
+		RegisterFormatter("TEST-PREFIX", func(options Options) (Formatter, error) { return nil, nil })
+	})
+
+	logger, err := NewLoggerFromConfig(&Config{
+		Level: INFO,
+		Formatter: FormatterConfig{
+			Type:    "test-prefix",
+			Options: Options{"prefix": "> "},
+		},
+		Writers: []WriterConfig{{Type: "buffer"}},
+	})
+	assert.Nil(t, err)
+	logger.Info("hello")
+	assert.Equal(t, "> hello\n", logger.GetWriter().(*BufferWriter).String())
+
+	_, err = NewWriter("missing", nil)
+	assert.NotNil(t, err)
+	_, err = NewFormatter("test-prefix", Options{"prefix": 1})
+	assert.NotNil(t, err)
+}
+
+func TestRegistryAsyncWriter(t *testing.T) {
+	w, err := NewWriter("async", Options{
+		"queue_size": float64(8),
+		"writer":     map[string]interface{}{"type": "buffer"},
+	})
+	assert.Nil(t, err)
+	assert.IsType(t, &AsyncWriter{}, w)
+	assert.Nil(t, w.Close())
+
+	_, err = NewWriter("async", Options{})
+	assert.NotNil(t, err)
+}
+
+func TestOptions(t *testing.T) {
+	options := Options{"n": float64(3), "f": 1.5, "s": "x", "b": true}
+	n, err := options.Int("n")
+	assert.Nil(t, err)
+	assert.Equal(t, 3, n)
+	_, err = options.Int("f")
+	assert.NotNil(t, err)
+	s, err := options.String("missing")
+	assert.Nil(t, err)
+	assert.Equal(t, "", s)
+	b, err := options.Bool("b")
+	assert.Nil(t, err)
+	assert.True(t, b)
+	_, err = options.Bool("s")
+	assert.NotNil(t, err)
+}
+
+func TestRegistryFileWriterSync(t *testing.T) {
+	dir := t.TempDir()
+	w, err := NewWriter("file", Options{
+		"filename":       filepath.Join(dir, "a.log"),
+		"buffer_size":    float64(0),
+		"flush_interval": "1s",
+		"sync":           "error",
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, SyncOnError, w.(*FileWriter).syncPolicy)
+	assert.Nil(t, w.Close())
+
+	w, err = NewWriter("file", Options{"filename": filepath.Join(dir, "b.log"), "sync": "2s"})
+	assert.Nil(t, err)
+	assert.Equal(t, SyncPeriodically, w.(*FileWriter).syncPolicy)
+	assert.Equal(t, 2*time.Second, w.(*FileWriter).syncInterval)
+	assert.Nil(t, w.Close())
+
+	_, err = NewWriter("file", Options{"filename": filepath.Join(dir, "c.log"), "sync": "sometimes"})
+	assert.NotNil(t, err)
+}
+
+func TestRegistryFileWriterReopenSignals(t *testing.T) {
+	dir := t.TempDir()
+	w, err := NewWriter("file", Options{
+		"filename":       filepath.Join(dir, "a.log"),
+		"reopen_signals": []interface{}{"SIGHUP", "hup"},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, []os.Signal{syscall.SIGHUP, syscall.SIGHUP}, w.(*FileWriter).reopenSignals)
+	assert.Nil(t, w.Close())
+
+	_, err = NewWriter("file", Options{"filename": filepath.Join(dir, "b.log"), "reopen_signals": "SIGNOPE"})
+	assert.NotNil(t, err)
+	_, err = NewWriter("file", Options{"filename": filepath.Join(dir, "c.log"), "reopen_signals": 1})
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,63 @@
+/*
+ * Copyright 2022 ByteDance and/or its affiliates.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dyclog
+
+import (
+	"math"
+	"sync/atomic"
+)
+
+// Sampler decides whether an entry of level is logged, it is consulted by
+// Logger before the entry is built, see Logger.SetSampler.
+type Sampler func(level Level) (keep bool)
+
+// NewSampler creates a Sampler which keeps rate of the entries less severe
+// than ERROR, spread evenly over the entries of each level starting with the
+// first one. ERROR and more severe entries are always kept.
+func NewSampler(rate float64) Sampler {
+	if rate >= 1 {
+		return func(level Level) bool {
+			return true
+		}
+	}
+	counters := make([]uint64, ERROR-TRACE+1)
+	return func(level Level) bool {
+		if level >= ERROR {
+			return true
+		}
+		if rate <= 0 {
+			return false
+		}
+		idx := level - TRACE
+		if idx < 0 {
+			idx = 0
+		}
+		// the n-th entry is kept when it brings the number of entries
+		// rate allows so far to the next integer.
+		n := float64(atomic.AddUint64(&counters[idx], 1) - 1)
+		return math.Floor(n*rate) > math.Floor((n-1)*rate)
+	}
+}
+
+// NewSamplingProcessor creates a Processor dropping entries like NewSampler,
+// prefer Logger.SetSampler which drops them before they are built.
+func NewSamplingProcessor(rate float64) Processor {
+	sampler := NewSampler(rate)
+	return func(entry *Entry) bool {
+		return sampler(entry.Level)
+	}
+}
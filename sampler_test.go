@@ -0,0 +1,72 @@
+/*
+ * Copyright 2022 ByteDance and/or its affiliates.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dyclog
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSamplingProcessor(t *testing.T) {
+	p := NewSamplingProcessor(0.25)
+	kept := 0
+	for i := 0; i < 100; i++ {
+		if p(&Entry{Level: INFO}) {
+			kept++
+		}
+	}
+	assert.Equal(t, 25, kept)
+	assert.True(t, p(&Entry{Level: DEBUG}))
+	for i := 0; i < 10; i++ {
+		assert.True(t, p(&Entry{Level: ERROR}))
+	}
+
+	p = NewSamplingProcessor(1)
+	assert.True(t, p(&Entry{Level: TRACE}))
+}
+
+func TestLoggerSampler(t *testing.T) {
+	logger := NewLogger(new(BufferWriter))
+	logger.SetSampler(NewSampler(0.5))
+	calls := 0
+	for i := 0; i < 4; i++ {
+		logger.Info("n=%v", countingValuer{calls: &calls, value: i})
+	}
+	logger.Error("always")
+	assert.Equal(t, 2, calls)
+	out := logger.GetWriter().(*BufferWriter).String()
+	assert.Contains(t, out, "n=0")
+	assert.Contains(t, out, "n=2")
+	assert.NotContains(t, out, "n=1")
+	assert.Contains(t, out, "always")
+}
+
+func TestSamplerRate(t *testing.T) {
+	for _, rate := range []float64{0.9, 0.7, 0.6, 0.5, 0.3, 0.01} {
+		s := NewSampler(rate)
+		kept := 0
+		for i := 0; i < 1000; i++ {
+			if s(INFO) {
+				kept++
+			}
+		}
+		assert.Equal(t, int(math.Round(1000*rate)), kept, rate)
+	}
+	assert.False(t, NewSampler(0)(INFO))
+}
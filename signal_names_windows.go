@@ -17,15 +17,11 @@
 package dyclog
 
 import (
-	"context"
-	"runtime"
-	"time"
+	"os"
+	"syscall"
 )
 
-type log struct {
-	caller  *runtime.Frame
-	time    time.Time
-	level   Level
-	message string
-	context context.Context
+// signalNames maps the names accepted by the reopen_signals option.
+var signalNames = map[string]os.Signal{
+	"HUP": syscall.SIGHUP,
 }
@@ -30,31 +30,57 @@ type syncWriter struct {
 	sync.Mutex
 }
 
-func newSyncWriter(w io.Writer) *syncWriter {
-	return &syncWriter{
-		Writer: bufio.NewWriterSize(w, 8*1024),
-	}
-}
+const (
+	defaultFlushSize     = 4096
+	defaultFlushInterval = 5 * time.Second
+	defaultSyncInterval  = time.Second
+)
 
 type rotatedFile struct {
 	w *syncWriter
+	// file is the file w writes to, it is guarded by w.
+	file io.WriteCloser
+	// flushSize is the size entries are buffered up to, 0 flushes every entry.
+	flushSize int
+	// syncOnClose syncs files to disk before closing them.
+	syncOnClose bool
 	sync.WaitGroup
 	done chan bool
 }
 
-func newRotatedFile(file io.WriteCloser) *rotatedFile {
+// newRotatedFile creates a rotatedFile flushed every flushInterval and synced
+// to disk every syncInterval if it is positive.
+func newRotatedFile(file io.WriteCloser, flushSize int, flushInterval, syncInterval time.Duration, syncOnClose bool) *rotatedFile {
+	size := 2 * flushSize
+	if size < defaultFlushSize {
+		size = defaultFlushSize
+	}
+	if flushInterval <= 0 {
+		flushInterval = defaultFlushInterval
+	}
 	f := &rotatedFile{
-		newSyncWriter(file),
-		sync.WaitGroup{},
-		make(chan bool),
+		w:           &syncWriter{Writer: bufio.NewWriterSize(file, size)},
+		file:        file,
+		flushSize:   flushSize,
+		syncOnClose: syncOnClose,
+		done:        make(chan bool),
 	}
 	f.Add(1)
-	ticker := time.NewTicker(5 * time.Second)
+	ticker := time.NewTicker(flushInterval)
+	var syncTicker *time.Ticker
+	var syncC <-chan time.Time
+	if syncInterval > 0 {
+		syncTicker = time.NewTicker(syncInterval)
+		syncC = syncTicker.C
+	}
 	go func() {
 		for {
 			select {
 			case <-f.done:
 				ticker.Stop()
+				if syncTicker != nil {
+					syncTicker.Stop()
+				}
 				_ = f.Flush()
 				f.Done()
 				return
@@ -63,22 +89,41 @@ func newRotatedFile(file io.WriteCloser) *rotatedFile {
 				if err != nil {
 					_, _ = fmt.Fprintf(os.Stderr, "log writes file error: %s", err)
 				}
+			case <-syncC:
+				err := f.Sync()
+				if err != nil {
+					_, _ = fmt.Fprintf(os.Stderr, "log syncs file error: %s\n", err)
+				}
 			}
 		}
 	}()
 	return f
 }
 
+// Close flushes the buffer and closes the file.
 func (f *rotatedFile) Close() error {
 	f.done <- true
 	f.Wait()
-	return nil
+	f.w.Lock()
+	defer f.w.Unlock()
+	if f.syncOnClose {
+		_ = f.syncFile()
+	}
+	return f.file.Close()
 }
 
+// Rotate flushes the buffer to the current file, closes it and writes to w.
 func (f *rotatedFile) Rotate(w io.WriteCloser) {
 	f.w.Lock()
 	defer f.w.Unlock()
 	_ = f.w.Flush()
+	if f.syncOnClose {
+		_ = f.syncFile()
+	}
+	if err := f.file.Close(); err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "log closes file error: %s\n", err)
+	}
+	f.file = w
 	f.w.Reset(w)
 }
 
@@ -93,10 +138,28 @@ func (f *rotatedFile) Flush() error {
 	return nil
 }
 
+// Sync flushes the buffer and commits the file to disk.
+func (f *rotatedFile) Sync() error {
+	f.w.Lock()
+	defer f.w.Unlock()
+	if err := f.w.Writer.Flush(); err != nil {
+		return err
+	}
+	return f.syncFile()
+}
+
+// syncFile commits the file to disk if it supports it, f.w must be locked.
+func (f *rotatedFile) syncFile() error {
+	if s, ok := f.file.(interface{ Sync() error }); ok {
+		return s.Sync()
+	}
+	return nil
+}
+
 func (f *rotatedFile) Write(c []byte) (int, error) {
 	f.w.Lock()
 	defer f.w.Unlock()
-	if f.w.Buffered()+len(c) > 4096 {
+	if f.w.Buffered()+len(c) > f.flushSize {
 		_ = f.w.Flush()
 	}
 	n, err := f.w.Write(c)
@@ -106,5 +169,8 @@ func (f *rotatedFile) Write(c []byte) (int, error) {
 	if len(c) == 0 || c[len(c)-1] != '\n' {
 		_, _ = f.w.Write([]byte{'\n'})
 	}
+	if f.flushSize <= 0 {
+		_ = f.w.Flush()
+	}
 	return n + 1, nil
 }
@@ -17,9 +17,8 @@
 package dyclog
 
 import (
-	"bytes"
 	"fmt"
-	"sync"
+	"strconv"
 )
 
 const (
@@ -29,34 +28,25 @@ const (
 	gray   = 37
 )
 
-var formatPool = sync.Pool{
-	New: func() interface{} {
-		return bytes.Buffer{}
-	},
-}
-
 type TextFormatter struct {
-	enableColors    bool
-	enableQuote     bool
-	enableTimestamp bool
-	timestampFormat string
+	fieldOptions
+	enableColors bool
+	enableQuote  bool
 }
 
 func NewDefaultTextFormatter() *TextFormatter {
 	return &TextFormatter{
-		enableColors:    false,
-		enableQuote:     false,
-		enableTimestamp: false,
-		timestampFormat: defaultTimestampFormat,
+		fieldOptions: newFieldOptions(false),
+		enableColors: false,
+		enableQuote:  false,
 	}
 }
 
 func NewTextFormatter(enableColor bool) *TextFormatter {
 	return &TextFormatter{
-		enableColors:    enableColor,
-		enableQuote:     false,
-		enableTimestamp: false,
-		timestampFormat: defaultTimestampFormat,
+		fieldOptions: newFieldOptions(false),
+		enableColors: enableColor,
+		enableQuote:  false,
 	}
 }
 
@@ -68,92 +58,81 @@ func (f *TextFormatter) SetQuote(enable bool) {
 	f.enableQuote = enable
 }
 
-func (f *TextFormatter) SetTimestamp(enable bool) {
-	f.enableTimestamp = enable
-}
-
 func (f *TextFormatter) isColored() bool {
 	return f.enableColors
 }
 
-func (f *TextFormatter) Format(l log) ([]byte, error) {
-	fixedKeys := make([]string, 0, 6)
-	if f.enableTimestamp {
-		fixedKeys = append(fixedKeys, fieldKeyTime)
-	}
-	fixedKeys = append(fixedKeys, fieldKeyLevel)
-	fixedKeys = append(fixedKeys, fieldKeyLogID)
-	fixedKeys = append(fixedKeys, fieldKeyLocation)
-	fixedKeys = append(fixedKeys, fieldKeyIP)
-	fixedKeys = append(fixedKeys, fieldKeyMessage)
-
-	b := formatPool.Get().(bytes.Buffer)
-	defer formatPool.Put(b)
+func (f *TextFormatter) Format(l *Entry, b *Buffer) error {
+	fixedKeys := f.appendFixedKeys(make([]string, 0, 7))
 
 	if f.isColored() {
-		f.encodeColorText(&b, l, fixedKeys)
+		f.encodeColorText(b, l, fixedKeys)
 	} else {
-		f.encodeText(&b, l, fixedKeys)
+		f.encodeText(b, l, fixedKeys)
 	}
 
-	b.WriteByte('\n')
-	return b.Bytes(), nil
+	_ = b.WriteByte('\n')
+	return nil
 }
 
-func (f *TextFormatter) encodeColorText(b *bytes.Buffer, entry log, fixedKeys []string) {
-	var levelColor int
-	switch entry.level {
-	case DEBUG:
-		levelColor = gray
-	case WARN:
-		levelColor = yellow
-	case ERROR, FATAL:
-		levelColor = red
-	case INFO:
-		levelColor = blue
-	default:
-		levelColor = blue
-	}
-
-	fmt.Fprintf(b, "\u001b[%dm", levelColor)
+func (f *TextFormatter) encodeColorText(b *Buffer, entry *Entry, fixedKeys []string) {
+	_, _ = b.WriteString("\u001b[")
+	b.AppendInt(int64(entry.Level.Color()))
+	_ = b.WriteByte('m')
 	f.encodeText(b, entry, fixedKeys)
 }
 
-func (f *TextFormatter) encodeText(b *bytes.Buffer, l log, fixedKeys []string) {
+func (f *TextFormatter) encodeText(b *Buffer, l *Entry, fixedKeys []string) {
 	for _, key := range fixedKeys {
-		var value interface{}
-		switch {
-		case key == fieldKeyTime:
-			value = l.time.Format(f.timestampFormat)
-		case key == fieldKeyIP:
-			value = GetLocalIP()
-		case key == fieldKeyLogID:
-			value = GetLogIDFromCtx(l.context)
-		case key == fieldKeyLevel:
-			value = l.level.String()
-		case key == fieldKeyMessage:
-			value = l.message
-		case key == fieldKeyLocation:
-			file, line := GetCallerLocation(l.caller)
-			value = fmt.Sprintf("%s:%d", file, line)
-		}
-
-		if value == nil {
+		if l.Caller == nil && (key == fieldKeyLocation || key == fieldKeyFunction) {
 			continue
 		}
 		if b.Len() > 0 {
-			b.WriteByte(' ')
+			_ = b.WriteByte(' ')
 		}
+		start := b.Len()
+		f.appendFixedValue(b, l, key)
+		f.quote(b, start)
+	}
 
-		stringVal, ok := value.(string)
-		if !ok {
-			stringVal = fmt.Sprint(value)
+	for _, field := range l.Fields {
+		if b.Len() > 0 {
+			_ = b.WriteByte(' ')
 		}
+		_, _ = b.WriteString(field.Key)
+		_ = b.WriteByte('=')
+		start := b.Len()
+		appendValue(b, field.Value)
+		f.quote(b, start)
+	}
+}
 
-		if !f.enableQuote {
-			b.WriteString(stringVal)
-		} else {
-			b.WriteString(fmt.Sprintf("%q", stringVal))
-		}
+// quote quotes the value written from start when quoting is enabled.
+func (f *TextFormatter) quote(b *Buffer, start int) {
+	if !f.enableQuote {
+		return
+	}
+	value := string(b.b[start:])
+	b.b = b.b[:start]
+	b.AppendQuote(value)
+}
+
+// appendValue writes the plain text of a field value.
+func appendValue(b *Buffer, value interface{}) {
+	switch v := resolveLogValue(value).(type) {
+	case string:
+		_, _ = b.WriteString(v)
+	case int:
+		b.AppendInt(int64(v))
+	case int64:
+		b.AppendInt(v)
+	case int32:
+		b.AppendInt(int64(v))
+	case bool:
+		b.b = strconv.AppendBool(b.b, v)
+	case error:
+		_, _ = b.WriteString(v.Error())
+	default:
+		_, _ = fmt.Fprint(b, v)
 	}
 }
@@ -25,16 +25,16 @@ import (
 
 func TestTextFormatter(t *testing.T) {
 	now := time.Now()
-	l := log{}
-	l.level = DEBUG
-	l.time = now
-	l.message = "test text formatter!"
-	l.context = context.Background()
-	l.caller = GetCaller(1)
+	l := &Entry{}
+	l.Level = DEBUG
+	l.Time = now
+	l.Message = "test text formatter!"
+	l.Context = context.Background()
+	l.Caller = GetCaller(1)
 
 	f := NewDefaultTextFormatter()
 	f.SetColor(true)
-	b, e := f.Format(l)
+	b, e := format(f, l)
 	if e != nil {
 		t.Errorf("err: %v", e)
 	}
@@ -42,14 +42,50 @@ func TestTextFormatter(t *testing.T) {
 	assert.Equal(t, "\x1b[37m DEBUG - text_formatter_test.go:33 "+ip+" test text formatter!\n", string(b))
 
 	f.SetColor(false)
-	b, e = f.Format(l)
+	b, e = format(f, l)
 	assert.Equal(t, "DEBUG - text_formatter_test.go:33 "+ip+" test text formatter!\n", string(b))
 
 	f.SetTimestamp(true)
-	b, e = f.Format(l)
+	b, e = format(f, l)
 	assert.Equal(t, now.Format(f.timestampFormat)+" DEBUG - text_formatter_test.go:33 "+ip+" test text formatter!\n", string(b))
 
 	f.SetQuote(true)
-	b, e = f.Format(l)
+	b, e = format(f, l)
 	assert.Equal(t, "\""+now.Format(f.timestampFormat)+"\""+" \"DEBUG\" \"-\" \"text_formatter_test.go:33\" \""+ip+"\" \"test text formatter!\"\n", string(b))
 }
+
+func TestTextFormatterIP(t *testing.T) {
+	l := &Entry{Level: INFO, Message: "ip", Caller: GetCaller(1)}
+
+	f := NewDefaultTextFormatter()
+	f.SetLocalIP("10.0.0.1")
+	b, _ := format(f, l)
+	assert.Equal(t, "INFO - text_formatter_test.go:58 10.0.0.1 ip\n", string(b))
+
+	f.SetIP(false)
+	b, _ = format(f, l)
+	assert.Equal(t, "INFO - text_formatter_test.go:58 ip\n", string(b))
+}
+
+func TestTextFormatterCaller(t *testing.T) {
+	l := &Entry{Level: INFO, Message: "caller", Caller: GetCaller(1)}
+
+	f := NewDefaultTextFormatter()
+	f.SetIP(false)
+	f.SetFunction(true)
+	b, _ := format(f, l)
+	assert.Equal(t, "INFO - text_formatter_test.go:71 go-dyclog.TestTextFormatterCaller caller\n", string(b))
+
+	f.SetFunction(false)
+	f.SetCallerFormat(CallerFullPath)
+	f.SetCallerTrimPrefix(l.Caller.File[:len(l.Caller.File)-len("text_formatter_test.go")])
+	b, _ = format(f, l)
+	assert.Equal(t, "INFO - text_formatter_test.go:71 caller\n", string(b))
+}
+
+func format(f Formatter, l *Entry) ([]byte, error) {
+	b := NewBuffer()
+	defer b.Free()
+	err := f.Format(l, b)
+	return []byte(b.String()), err
+}
@@ -21,6 +21,20 @@ import (
 	"net/http"
 	"runtime"
 	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// CallerFormat decides how the file of the caller location is written.
+type CallerFormat int8
+
+const (
+	// CallerShortFile writes the base filename, e.g. logger.go:12.
+	CallerShortFile CallerFormat = iota
+	// CallerPackageFile writes the package directory and filename, e.g. dyclog/logger.go:12.
+	CallerPackageFile
+	// CallerFullPath writes the full path without the configured trim prefix.
+	CallerFullPath
 )
 
 func GetCallerLocation(caller *runtime.Frame) (string, int) {
@@ -34,6 +48,38 @@ func GetCallerLocation(caller *runtime.Frame) (string, int) {
 	return file[lastSlash+1:], line
 }
 
+// GetCallerFile returns the file of caller in the given format, trimPrefix
+// is only stripped from the full path.
+func GetCallerFile(caller *runtime.Frame, format CallerFormat, trimPrefix string) string {
+	switch format {
+	case CallerPackageFile:
+		file := caller.File
+		lastSlash := strings.LastIndex(file, "/")
+		if lastSlash == -1 {
+			return file
+		}
+		if dirSlash := strings.LastIndex(file[:lastSlash], "/"); dirSlash != -1 {
+			return file[dirSlash+1:]
+		}
+		return file
+	case CallerFullPath:
+		return strings.TrimPrefix(caller.File, trimPrefix)
+	default:
+		file, _ := GetCallerLocation(caller)
+		return file
+	}
+}
+
+// GetCallerFunction returns the function name of caller without the import
+// path of its package, e.g. dyclog.(*Logger).Info.
+func GetCallerFunction(caller *runtime.Frame) string {
+	function := caller.Function
+	if lastSlash := strings.LastIndex(function, "/"); lastSlash != -1 {
+		function = function[lastSlash+1:]
+	}
+	return function
+}
+
 func GetRemoteIP(req *http.Request) string {
 	remoteAddr := req.RemoteAddr
 	if ip := req.Header.Get("X-Real-IP"); ip != "" {
@@ -51,25 +97,87 @@ func GetRemoteIP(req *http.Request) string {
 	return remoteAddr
 }
 
+// GetLocalIP returns the first non-loopback IPv4 address of the host, or
+// its first global unicast IPv6 address on IPv6-only hosts. It enumerates
+// network interfaces on every call, use an IPResolver to cache the result.
 func GetLocalIP() string {
 	addrs, err := net.InterfaceAddrs()
 	if err != nil {
 		return ""
 	}
+	return pickIP(addrs)
+}
+
+// GetInterfaceIP is like GetLocalIP but only looks at the named interface.
+func GetInterfaceIP(name string) string {
+	iface, err := net.InterfaceByName(name)
+	if err != nil {
+		return ""
+	}
+	addrs, err := iface.Addrs()
+	if err != nil {
+		return ""
+	}
+	return pickIP(addrs)
+}
+
+func pickIP(addrs []net.Addr) string {
+	var ipv6 string
 	for _, address := range addrs {
 		if ipnet, ok := address.(*net.IPNet); ok && !ipnet.IP.IsLoopback() {
 			if ipnet.IP.To4() != nil {
 				return ipnet.IP.String()
 			}
+			if ipv6 == "" && ipnet.IP.IsGlobalUnicast() {
+				ipv6 = ipnet.IP.String()
+			}
 		}
 	}
-	return ""
+	return ipv6
 }
 
+// GetCaller returns the frame of the caller depth levels above, frames are
+// cached by program counter and shared, so the result must not be modified.
 func GetCaller(depth int) *runtime.Frame {
-	pcs := make([]uintptr, 10)
-	_ = runtime.Callers(depth+1, pcs)
-	frames := runtime.CallersFrames(pcs)
-	f, _ := frames.Next()
+	var pcs [1]uintptr
+	if runtime.Callers(depth+1, pcs[:]) < 1 {
+		return nil
+	}
+	return callerCache.frame(pcs[0])
+}
+
+var callerCache = newFrameCache()
+
+// frameCache is a copy-on-write map from program counter to frame, lookups
+// are lock-free and only the first log call of each call site writes.
+type frameCache struct {
+	frames atomic.Value
+	sync.Mutex
+}
+
+func newFrameCache() *frameCache {
+	c := &frameCache{}
+	c.frames.Store(make(map[uintptr]*runtime.Frame))
+	return c
+}
+
+func (c *frameCache) frame(pc uintptr) *runtime.Frame {
+	if f, ok := c.frames.Load().(map[uintptr]*runtime.Frame)[pc]; ok {
+		return f
+	}
+
+	c.Lock()
+	defer c.Unlock()
+	frames := c.frames.Load().(map[uintptr]*runtime.Frame)
+	if f, ok := frames[pc]; ok {
+		return f
+	}
+	f, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	updated := make(map[uintptr]*runtime.Frame, len(frames)+1)
+	for k, v := range frames {
+		updated[k] = v
+	}
+	updated[pc] = &f
+	c.frames.Store(updated)
 	return &f
 }
@@ -0,0 +1,59 @@
+/*
+ * Copyright 2022 ByteDance and/or its affiliates.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dyclog
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetCallerFile(t *testing.T) {
+	caller := &runtime.Frame{
+		File:     "/home/user/go/src/github.com/bytedance/go-dyclog/logger.go",
+		Line:     12,
+		Function: "github.com/bytedance/go-dyclog.(*Logger).Info",
+	}
+	assert.Equal(t, "logger.go", GetCallerFile(caller, CallerShortFile, ""))
+	assert.Equal(t, "go-dyclog/logger.go", GetCallerFile(caller, CallerPackageFile, ""))
+	assert.Equal(t, caller.File, GetCallerFile(caller, CallerFullPath, ""))
+	assert.Equal(t, "github.com/bytedance/go-dyclog/logger.go", GetCallerFile(caller, CallerFullPath, "/home/user/go/src/"))
+	assert.Equal(t, "go-dyclog.(*Logger).Info", GetCallerFunction(caller))
+}
+
+func TestGetCallerCached(t *testing.T) {
+	callers := make([]*runtime.Frame, 0, 2)
+	for i := 0; i < 2; i++ {
+		callers = append(callers, GetCaller(1))
+	}
+	assert.Equal(t, "utils_test.go", GetCallerFile(callers[0], CallerShortFile, ""))
+	assert.Equal(t, "github.com/bytedance/go-dyclog.TestGetCallerCached", callers[0].Function)
+	assert.True(t, callers[0] == callers[1])
+	assert.Equal(t, float64(0), testing.AllocsPerRun(10, func() { _ = GetCaller(1) }))
+}
+
+func TestLoggerWithoutCaller(t *testing.T) {
+	logger := NewLogger(new(BufferWriter))
+	formatter := NewDefaultTextFormatter()
+	formatter.SetIP(false)
+	formatter.SetFunction(true)
+	logger.SetFormatter(formatter)
+	logger.SetCaller(false)
+	logger.Info("no caller")
+	assert.Equal(t, "INFO - no caller\n", logger.GetWriter().(*BufferWriter).String())
+}
@@ -0,0 +1,55 @@
+/*
+ * Copyright 2022 ByteDance and/or its affiliates.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dyclog
+
+const maxLogValuerDepth = 16
+
+// LogValuer is implemented by arguments and field values which are costly to
+// compute. LogValue is only called once the entry passed the level check, so
+// filtered entries never pay for it.
+type LogValuer interface {
+	LogValue() interface{}
+}
+
+// resolveLogValue calls LogValue until the result is no longer a LogValuer.
+func resolveLogValue(v interface{}) interface{} {
+	for i := 0; i < maxLogValuerDepth; i++ {
+		valuer, ok := v.(LogValuer)
+		if !ok {
+			return v
+		}
+		v = valuer.LogValue()
+	}
+	return v
+}
+
+// resolveArgs returns args with every LogValuer resolved, args is only
+// copied when it contains any LogValuer.
+func resolveArgs(args []interface{}) []interface{} {
+	for i, arg := range args {
+		if _, ok := arg.(LogValuer); !ok {
+			continue
+		}
+		resolved := make([]interface{}, len(args))
+		copy(resolved, args[:i])
+		for j := i; j < len(args); j++ {
+			resolved[j] = resolveLogValue(args[j])
+		}
+		return resolved
+	}
+	return args
+}
@@ -0,0 +1,69 @@
+/*
+ * Copyright 2022 ByteDance and/or its affiliates.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dyclog
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type countingValuer struct {
+	calls *int
+	value interface{}
+}
+
+func (v countingValuer) LogValue() interface{} {
+	*v.calls++
+	return v.value
+}
+
+func TestLoggerEnabled(t *testing.T) {
+	logger := NewLogger(new(BufferWriter))
+	logger.SetLevel(WARN)
+	assert.False(t, logger.Enabled(context.Background(), INFO))
+	assert.True(t, logger.Enabled(context.Background(), WARN))
+	assert.True(t, logger.Enabled(context.Background(), FATAL))
+}
+
+func TestLogValuer(t *testing.T) {
+	logger := NewLogger(new(BufferWriter))
+	logger.SetLevel(INFO)
+	calls := 0
+	body := countingValuer{calls: &calls, value: countingValuer{calls: &calls, value: "big body"}}
+
+	logger.Debug("body: %v", body)
+	assert.Equal(t, 0, calls)
+
+	args := []interface{}{1, body}
+	logger.Info("id: %d, body: %v", args...)
+	assert.Equal(t, 2, calls)
+	assert.Equal(t, body, args[1])
+	assert.True(t, strings.HasSuffix(logger.GetWriter().(*BufferWriter).String(), " id: 1, body: big body\n"))
+
+	logger.AddProcessor(func(entry *Entry) bool {
+		entry.AddField("req", body)
+		return entry.Level >= ERROR
+	})
+	logger.Warn("dropped")
+	assert.Equal(t, 2, calls)
+	logger.Error("kept")
+	assert.Equal(t, 4, calls)
+	assert.True(t, strings.HasSuffix(logger.GetWriter().(*BufferWriter).String(), " kept req=big body\n"))
+}
@@ -20,8 +20,33 @@ import (
 	"io"
 )
 
+// LogWriter writes formatted entries, Write must not retain log after it
+// returns since the memory is reused for the following entries.
 type LogWriter interface {
 	io.Closer
 	Write(log []byte) error
 	Flush() error
 }
+
+// BufferLogWriter is implemented by writers that keep formatted entries after
+// Write returns, such as AsyncWriter. Logger hands the ownership of the Buffer
+// to WriteBuffer, which must call Free once the Buffer is no longer used.
+type BufferLogWriter interface {
+	LogWriter
+	WriteBuffer(b *Buffer) error
+}
+
+// LevelLogWriter is implemented by writers that handle entries depending on
+// their level, such as FileWriter syncing ERROR entries to disk.
+type LevelLogWriter interface {
+	LogWriter
+	WriteLevel(level Level, log []byte) error
+}
+
+// writeLevel writes log with WriteLevel if w implements it, or Write.
+func writeLevel(w LogWriter, level Level, log []byte) error {
+	if lw, ok := w.(LevelLogWriter); ok {
+		return lw.WriteLevel(level, log)
+	}
+	return w.Write(log)
+}